@@ -1,12 +1,41 @@
 package main
 
 import (
+	"fmt"
 	"log/slog"
 	"notifybot/internal/bot"
 	"os"
 	"strings"
+	"time"
+
+	"gopkg.in/yaml.v2"
 )
 
+// loadConfig builds the bot Config either from a YAML file (when CONFIG_FILE
+// is set, supporting multiple notifier stanzas) or from the legacy
+// environment variables, which configure a single SES notifier. It doubles
+// as the bot's ReloadFunc, so the admin `reload` command re-reads from the
+// same source the bot started with.
+func loadConfig() (*bot.Config, error) {
+	if path := os.Getenv("CONFIG_FILE"); path != "" {
+		return loadConfigFromYAML(path)
+	}
+	return loadConfigFromEnv(), nil
+}
+
+func loadConfigFromYAML(path string) (*bot.Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading config file %q: %w", path, err)
+	}
+
+	var config bot.Config
+	if err := yaml.Unmarshal(data, &config); err != nil {
+		return nil, fmt.Errorf("parsing config file %q: %w", path, err)
+	}
+	return &config, nil
+}
+
 func loadConfigFromEnv() *bot.Config {
 	requiredVars := []string{"SERVER", "PORT", "BOT_NAME", "NOTIFY_EMAIL", "FROM_EMAIL", "AWS_REGION"}
 	for _, v := range requiredVars {
@@ -15,16 +44,56 @@ func loadConfigFromEnv() *bot.Config {
 		}
 	}
 
-	return &bot.Config{
-		Server:      os.Getenv("SERVER"),
-		Port:        os.Getenv("PORT"),
-		BotName:     os.Getenv("BOT_NAME"),
-		Channels:    strings.Split(os.Getenv("CHANNELS"), ","),
-		NotifyEmail: os.Getenv("NOTIFY_EMAIL"),
-		FromEmail:   os.Getenv("FROM_EMAIL"),
-		SleepMin:    os.Getenv("SLEEP_MIN"),
-		AwsRegion:   os.Getenv("AWS_REGION"),
+	config := &bot.Config{
+		Server:             os.Getenv("SERVER"),
+		Port:               os.Getenv("PORT"),
+		BotName:            os.Getenv("BOT_NAME"),
+		Channels:           strings.Split(os.Getenv("CHANNELS"), ","),
+		SleepMin:           os.Getenv("SLEEP_MIN"),
+		TLS:                os.Getenv("TLS") == "true",
+		InsecureSkipVerify: os.Getenv("INSECURE_SKIP_VERIFY") == "true",
+		SASLUser:           os.Getenv("SASL_USER"),
+		SASLPass:           os.Getenv("SASL_PASS"),
+		Admins:             strings.Split(os.Getenv("ADMINS"), ","),
+		Notifiers: []bot.NotifierConfig{
+			{
+				Type: "ses",
+				SES: &bot.SESNotifierConfig{
+					Region:      os.Getenv("AWS_REGION"),
+					FromEmail:   os.Getenv("FROM_EMAIL"),
+					NotifyEmail: os.Getenv("NOTIFY_EMAIL"),
+				},
+			},
+		},
+	}
+
+	if path := os.Getenv("STATE_STORE_PATH"); path != "" {
+		storeType := os.Getenv("STATE_STORE_TYPE")
+		if storeType == "" {
+			storeType = "json"
+		}
+		config.StateStore = bot.StateStoreConfig{Type: storeType, Path: path}
+	}
+
+	if grace := os.Getenv("COLD_START_GRACE"); grace != "" {
+		duration, err := time.ParseDuration(grace)
+		if err != nil {
+			slog.Error("Error parsing COLD_START_GRACE, ignoring", "value", grace, "error", err)
+		} else {
+			config.ColdStartGrace = duration
+		}
+	}
+
+	if timeout := os.Getenv("PING_TIMEOUT"); timeout != "" {
+		duration, err := time.ParseDuration(timeout)
+		if err != nil {
+			slog.Error("Error parsing PING_TIMEOUT, ignoring", "value", timeout, "error", err)
+		} else {
+			config.PingTimeout = duration
+		}
 	}
+
+	return config
 }
 
 func loadNicknamesFromEnv() map[string]bool {
@@ -40,10 +109,15 @@ func main() {
 	log := slog.New(slog.NewTextHandler(os.Stdout, nil))
 	log.Info("Starting NotifyBot")
 
-	config := loadConfigFromEnv()
+	config, err := loadConfig()
+	if err != nil {
+		log.Error("Failed to load configuration", "error", err)
+		os.Exit(1)
+	}
 	log.Info("Configuration loaded successfully")
 	nicknames := loadNicknamesFromEnv()
 
 	notifyBot := bot.NewNotifyBot(config, log, nicknames)
+	notifyBot.SetReloadFunc(loadConfig)
 	notifyBot.Run()
 }
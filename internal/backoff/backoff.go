@@ -0,0 +1,43 @@
+// Package backoff implements exponential backoff with full jitter, as
+// described in https://aws.amazon.com/blogs/architecture/exponential-backoff-and-jitter/.
+// It replaces flat "sleep N minutes and retry" loops with a curve that
+// retries quickly at first and backs off as failures continue, without
+// every client thundering back in lockstep.
+package backoff
+
+import (
+	"math"
+	"math/rand"
+	"time"
+)
+
+// Policy computes retry delays following an exponential backoff curve with
+// full jitter: each delay is chosen uniformly at random between 0 and
+// min(Cap, Base*Factor^(attempt-1)).
+type Policy struct {
+	Base   time.Duration
+	Factor float64
+	Cap    time.Duration
+}
+
+// Default is the policy used for IRC reconnects: a 2s base, doubling each
+// attempt, capped at 5 minutes.
+var Default = Policy{Base: 2 * time.Second, Factor: 2, Cap: 5 * time.Minute}
+
+// Next returns the delay to wait before retrying the given attempt number.
+// Attempt is 1-indexed: the first retry is attempt 1.
+func (p Policy) Next(attempt int) time.Duration {
+	if attempt < 1 {
+		attempt = 1
+	}
+
+	max := float64(p.Base) * math.Pow(p.Factor, float64(attempt-1))
+	if max > float64(p.Cap) {
+		max = float64(p.Cap)
+	}
+	if max <= 0 {
+		return 0
+	}
+
+	return time.Duration(rand.Int63n(int64(max) + 1))
+}
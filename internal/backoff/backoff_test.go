@@ -0,0 +1,36 @@
+package backoff
+
+import "testing"
+
+func TestPolicy_Next_withinBounds(t *testing.T) {
+	p := Policy{Base: 2, Factor: 2, Cap: 100}
+
+	cases := []struct {
+		attempt int
+		wantMax int64
+	}{
+		{attempt: 0, wantMax: 2}, // clamped up to attempt 1
+		{attempt: 1, wantMax: 2},
+		{attempt: 2, wantMax: 4},
+		{attempt: 3, wantMax: 8},
+		{attempt: 10, wantMax: 100}, // capped
+	}
+
+	for _, c := range cases {
+		for i := 0; i < 50; i++ {
+			got := p.Next(c.attempt)
+			if got < 0 || int64(got) > c.wantMax {
+				t.Fatalf("Next(%d) = %d, want in [0, %d]", c.attempt, got, c.wantMax)
+			}
+		}
+	}
+}
+
+func TestPolicy_Next_capIsRespectedOverManyAttempts(t *testing.T) {
+	p := Default
+	for i := 0; i < 50; i++ {
+		if got := p.Next(100); got > p.Cap {
+			t.Fatalf("Next(100) = %s, want <= cap %s", got, p.Cap)
+		}
+	}
+}
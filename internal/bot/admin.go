@@ -0,0 +1,246 @@
+package bot
+
+import (
+	"fmt"
+	"net"
+	"sort"
+	"strings"
+	"time"
+)
+
+// commandText recognizes the two ways an admin command can be sent: a
+// private message to the bot (the whole trailing text is the command), or
+// an in-channel message prefixed with "!".
+func commandText(text, target, botName string) (string, bool) {
+	if target == botName {
+		return text, true
+	}
+	if strings.HasPrefix(text, "!") {
+		return strings.TrimPrefix(text, "!"), true
+	}
+	return "", false
+}
+
+// isAdmin reports whether sender (a full nick!user@host mask) matches one
+// of the allow-listed admin masks in Config.Admins. Masks must cover the
+// full nick!user@host form, optionally with "*" as a wildcard, e.g.
+// "alice!*@*" or "*!*@trusted.example.com". A bare nickname such as
+// "alice" is deliberately NOT accepted as a mask: it would grant admin
+// rights to whoever currently holds that nick, with no verification of
+// user@host at all, which defeats the entire point of an allow-list.
+func (b *NotifyBot) isAdmin(sender string) bool {
+	for _, mask := range b.conf.Admins {
+		if matchesMask(sender, mask) {
+			return true
+		}
+	}
+	return false
+}
+
+func matchesMask(sender, mask string) bool {
+	if !strings.Contains(mask, "*") {
+		return sender == mask
+	}
+	parts := strings.Split(mask, "*")
+	pos := 0
+	for i, part := range parts {
+		if part == "" {
+			continue
+		}
+		idx := strings.Index(sender[pos:], part)
+		if idx == -1 {
+			return false
+		}
+		if i == 0 && idx != 0 {
+			return false
+		}
+		pos += idx + len(part)
+	}
+	return parts[len(parts)-1] == "" || strings.HasSuffix(sender, parts[len(parts)-1])
+}
+
+// handleCommand implements the admin command surface: watch/unwatch/list/
+// join/part/status/reload. The sender must match one of Config.Admins.
+// Results are always NOTICEd back to the sender's nick.
+func (b *NotifyBot) handleCommand(conn net.Conn, sender, text string) {
+	replyTo := strings.Split(sender, "!")[0]
+
+	if !b.isAdmin(sender) {
+		b.log.Info("Ignoring command from non-admin", "sender", sender)
+		return
+	}
+
+	fields := strings.Fields(text)
+	if len(fields) == 0 {
+		return
+	}
+	cmd, args := fields[0], fields[1:]
+
+	var reply string
+	switch cmd {
+	case "watch":
+		reply = b.cmdWatch(args)
+	case "unwatch":
+		reply = b.cmdUnwatch(args)
+	case "list":
+		reply = b.cmdList()
+	case "join":
+		reply = b.cmdJoin(conn, args)
+	case "part":
+		reply = b.cmdPart(conn, args)
+	case "status":
+		reply = b.cmdStatus()
+	case "reload":
+		reply = b.cmdReload()
+	default:
+		reply = fmt.Sprintf("Unknown command: %s", cmd)
+	}
+
+	b.log.Info("Admin command handled", "sender", sender, "command", cmd)
+	fmt.Fprintf(conn, "NOTICE %s :%s\r\n", replyTo, reply)
+}
+
+func (b *NotifyBot) cmdWatch(args []string) string {
+	if len(args) != 1 {
+		return "Usage: watch <nick>"
+	}
+	nick := args[0]
+
+	b.mu.Lock()
+	b.nicknames[nick] = false
+	b.mu.Unlock()
+
+	b.persistState(nick, false)
+	return fmt.Sprintf("Now watching %s", nick)
+}
+
+func (b *NotifyBot) cmdUnwatch(args []string) string {
+	if len(args) != 1 {
+		return "Usage: unwatch <nick>"
+	}
+	nick := args[0]
+
+	b.mu.Lock()
+	delete(b.nicknames, nick)
+	b.mu.Unlock()
+
+	return fmt.Sprintf("No longer watching %s", nick)
+}
+
+func (b *NotifyBot) cmdList() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if len(b.nicknames) == 0 {
+		return "Not watching any nicknames"
+	}
+	var entries []string
+	for nick, online := range b.nicknames {
+		state := "offline"
+		if online {
+			state = "online"
+		}
+		entries = append(entries, fmt.Sprintf("%s(%s)", nick, state))
+	}
+	sort.Strings(entries)
+	return "Watching: " + strings.Join(entries, " ")
+}
+
+func (b *NotifyBot) cmdJoin(conn net.Conn, args []string) string {
+	if len(args) != 1 {
+		return "Usage: join <#channel>"
+	}
+	channel := args[0]
+
+	b.mu.Lock()
+	if !slicesContains(b.channels, channel) {
+		b.channels = append(b.channels, channel)
+	}
+	b.mu.Unlock()
+
+	fmt.Fprintf(conn, "JOIN %s\r\n", channel)
+	return fmt.Sprintf("Joined %s", channel)
+}
+
+func (b *NotifyBot) cmdPart(conn net.Conn, args []string) string {
+	if len(args) != 1 {
+		return "Usage: part <#channel>"
+	}
+	channel := args[0]
+
+	b.mu.Lock()
+	b.channels = removeString(b.channels, channel)
+	b.mu.Unlock()
+
+	fmt.Fprintf(conn, "PART %s\r\n", channel)
+	return fmt.Sprintf("Left %s", channel)
+}
+
+func (b *NotifyBot) cmdStatus() string {
+	b.mu.Lock()
+	watched := len(b.nicknames)
+	channels := append([]string(nil), b.channels...)
+	b.mu.Unlock()
+
+	return fmt.Sprintf("Connected=%t watching=%d channels=%s uptime=%s",
+		b.connected, watched, strings.Join(channels, ","), time.Since(b.bootTime).Round(time.Second))
+}
+
+// cmdReload re-fetches configuration via the bot's ReloadFunc (if one was
+// set with SetReloadFunc) and rebuilds the notifiers from it. It does not
+// affect the watched nickname set or joined channels, which are runtime
+// state managed independently by watch/unwatch/join/part.
+func (b *NotifyBot) cmdReload() string {
+	if b.reloadFunc == nil {
+		return "Reload is not configured"
+	}
+
+	config, err := b.reloadFunc()
+	if err != nil {
+		b.log.Error("Failed to reload configuration", "error", err)
+		return fmt.Sprintf("Reload failed: %v", err)
+	}
+
+	notifiers, err := buildNotifiers(config.Notifiers, b.log)
+	if err != nil {
+		b.log.Error("Failed to rebuild notifiers on reload", "error", err)
+		return fmt.Sprintf("Reload failed: %v", err)
+	}
+
+	var subFilter *subscriptionFilter
+	if len(config.Subscriptions) > 0 {
+		subFilter = newSubscriptionFilter(config.Subscriptions, notifiersByName(config.Notifiers, notifiers), b.log)
+	}
+
+	b.mu.Lock()
+	oldFilter := b.subFilter
+	b.conf = config
+	b.notifiers = notifiers
+	b.subFilter = subFilter
+	b.mu.Unlock()
+
+	if oldFilter != nil {
+		oldFilter.Stop()
+	}
+
+	return "Configuration reloaded"
+}
+
+func slicesContains(haystack []string, needle string) bool {
+	for _, s := range haystack {
+		if s == needle {
+			return true
+		}
+	}
+	return false
+}
+
+func removeString(haystack []string, needle string) []string {
+	out := haystack[:0]
+	for _, s := range haystack {
+		if s != needle {
+			out = append(out, s)
+		}
+	}
+	return out
+}
@@ -0,0 +1,255 @@
+package bot
+
+import (
+	"bytes"
+	"errors"
+	"log/slog"
+	"net"
+	"strings"
+	"testing"
+	"time"
+)
+
+// recordingConn is a minimal net.Conn whose Write actually appends to its
+// buffer, unlike dummyConn (used elsewhere for write-and-forget tests),
+// so admin command replies can be asserted on.
+type recordingConn struct {
+	bytes.Buffer
+}
+
+func (c *recordingConn) Read(b []byte) (int, error)         { return 0, nil }
+func (c *recordingConn) Close() error                       { return nil }
+func (c *recordingConn) LocalAddr() net.Addr                { return nil }
+func (c *recordingConn) RemoteAddr() net.Addr               { return nil }
+func (c *recordingConn) SetDeadline(t time.Time) error      { return nil }
+func (c *recordingConn) SetReadDeadline(t time.Time) error  { return nil }
+func (c *recordingConn) SetWriteDeadline(t time.Time) error { return nil }
+
+func newTestBot(t *testing.T, admins []string) (*NotifyBot, *recordingConn) {
+	t.Helper()
+	conf := &Config{
+		BotName:        "notifybot",
+		Admins:         admins,
+		ColdStartGrace: 0,
+		Channels:       []string{"#watchers"},
+	}
+	log := slog.New(slog.NewTextHandler(&bytes.Buffer{}, nil))
+	bot := NewNotifyBot(conf, log, map[string]bool{"alice": false})
+	return bot, &recordingConn{}
+}
+
+func TestMatchesMask(t *testing.T) {
+	cases := []struct {
+		sender, mask string
+		want         bool
+	}{
+		// A bare nickname is not a verified mask and must not match: it
+		// would grant admin rights to whoever currently holds that nick.
+		{"alice!user@host", "alice", false},
+		{"alice!user@host", "bob", false},
+		{"alice!user@host", "alice!*@*", true},
+		{"alice!user@host", "alice!user@host", true},
+		{"bob!user@host", "alice!*@*", false},
+		{"alice!user@example.com", "*!*@example.com", true},
+	}
+	for _, c := range cases {
+		if got := matchesMask(c.sender, c.mask); got != c.want {
+			t.Errorf("matchesMask(%q, %q) = %v, want %v", c.sender, c.mask, got, c.want)
+		}
+	}
+}
+
+func TestHandleCommand_nonAdminIgnored(t *testing.T) {
+	bot, conn := newTestBot(t, []string{"alice!*@*"})
+
+	bot.handleCommand(conn, "mallory!user@host", "watch bob")
+
+	if conn.String() != "" {
+		t.Errorf("expected no reply for non-admin, got %q", conn.String())
+	}
+	if _, ok := bot.nicknames["bob"]; ok {
+		t.Error("non-admin should not be able to add a watched nick")
+	}
+}
+
+func TestHandleCommand_watchAndUnwatch(t *testing.T) {
+	bot, conn := newTestBot(t, []string{"alice!*@*"})
+
+	bot.handleCommand(conn, "alice!user@host", "watch bob")
+	if online, ok := bot.nicknames["bob"]; !ok || online {
+		t.Fatal("expected bob to be watched and default to offline")
+	}
+	if !strings.Contains(conn.String(), "NOTICE alice :Now watching bob") {
+		t.Errorf("unexpected reply: %q", conn.String())
+	}
+	conn.Reset()
+
+	bot.handleCommand(conn, "alice!user@host", "unwatch bob")
+	if _, ok := bot.nicknames["bob"]; ok {
+		t.Error("expected bob to no longer be watched")
+	}
+	if !strings.Contains(conn.String(), "NOTICE alice :No longer watching bob") {
+		t.Errorf("unexpected reply: %q", conn.String())
+	}
+}
+
+func TestHandleCommand_joinAndPart(t *testing.T) {
+	bot, conn := newTestBot(t, []string{"alice!*@*"})
+
+	bot.handleCommand(conn, "alice!user@host", "join #new-room")
+	if !slicesContains(bot.channels, "#new-room") {
+		t.Error("expected #new-room to be joined")
+	}
+	if !strings.Contains(conn.String(), "JOIN #new-room") {
+		t.Errorf("expected a JOIN to be sent, got %q", conn.String())
+	}
+	conn.Reset()
+
+	bot.handleCommand(conn, "alice!user@host", "part #new-room")
+	if slicesContains(bot.channels, "#new-room") {
+		t.Error("expected #new-room to be parted")
+	}
+	if !strings.Contains(conn.String(), "PART #new-room") {
+		t.Errorf("expected a PART to be sent, got %q", conn.String())
+	}
+}
+
+func TestCmdList(t *testing.T) {
+	bot, _ := newTestBot(t, []string{"alice!*@*"})
+
+	bot.mu.Lock()
+	bot.nicknames = map[string]bool{"bob": true, "carol": false}
+	bot.mu.Unlock()
+
+	got := bot.cmdList()
+	if !strings.Contains(got, "bob(online)") || !strings.Contains(got, "carol(offline)") {
+		t.Errorf("unexpected cmdList output: %q", got)
+	}
+}
+
+func TestCmdList_noneWatched(t *testing.T) {
+	bot, _ := newTestBot(t, []string{"alice!*@*"})
+
+	bot.mu.Lock()
+	bot.nicknames = map[string]bool{}
+	bot.mu.Unlock()
+
+	if got := bot.cmdList(); got != "Not watching any nicknames" {
+		t.Errorf("cmdList() = %q, want the not-watching-anything message", got)
+	}
+}
+
+func TestCmdStatus(t *testing.T) {
+	bot, _ := newTestBot(t, []string{"alice!*@*"})
+
+	bot.mu.Lock()
+	bot.connected = true
+	bot.channels = []string{"#watchers", "#ops"}
+	bot.mu.Unlock()
+
+	got := bot.cmdStatus()
+	if !strings.Contains(got, "Connected=true") {
+		t.Errorf("expected cmdStatus to report Connected=true, got %q", got)
+	}
+	if !strings.Contains(got, "watching=1") {
+		t.Errorf("expected cmdStatus to report watching=1, got %q", got)
+	}
+	if !strings.Contains(got, "channels=#watchers,#ops") {
+		t.Errorf("expected cmdStatus to report the joined channels, got %q", got)
+	}
+}
+
+func TestCmdReload_notConfigured(t *testing.T) {
+	bot, _ := newTestBot(t, []string{"alice!*@*"})
+
+	if got := bot.cmdReload(); got != "Reload is not configured" {
+		t.Errorf("cmdReload() = %q, want the not-configured message", got)
+	}
+}
+
+func TestCmdReload_success(t *testing.T) {
+	bot, _ := newTestBot(t, []string{"alice!*@*"})
+
+	oldFilter := newSubscriptionFilter(
+		[]Subscription{{Recipient: "ops"}},
+		map[string]Notifier{"ops": &mockNotifier{}},
+		testLogger(),
+	)
+	bot.subFilter = oldFilter
+
+	newConf := &Config{
+		BotName: "notifybot",
+		Admins:  []string{"alice!*@*"},
+		Notifiers: []NotifierConfig{
+			{Type: "webhook", Name: "ops", Webhook: &WebhookNotifierConfig{URL: "http://example.invalid"}},
+		},
+		Subscriptions: []Subscription{{Recipient: "ops"}},
+	}
+	bot.SetReloadFunc(func() (*Config, error) { return newConf, nil })
+
+	if got := bot.cmdReload(); got != "Configuration reloaded" {
+		t.Fatalf("cmdReload() = %q, want success message", got)
+	}
+
+	if bot.conf != newConf {
+		t.Error("expected b.conf to be replaced with the reloaded config")
+	}
+	if bot.subFilter == oldFilter {
+		t.Error("expected b.subFilter to be replaced with a filter built from the reloaded config")
+	}
+	if len(bot.notifiers) != 1 {
+		t.Fatalf("expected 1 rebuilt notifier, got %d", len(bot.notifiers))
+	}
+
+	select {
+	case <-oldFilter.stop:
+	default:
+		t.Error("expected the old subscription filter's flush loop to be stopped")
+	}
+}
+
+func TestCmdReload_failurePreservesState(t *testing.T) {
+	bot, _ := newTestBot(t, []string{"alice!*@*"})
+
+	origConf := bot.conf
+	origNotifiers := bot.notifiers
+	origFilter := newSubscriptionFilter(nil, nil, testLogger())
+	bot.subFilter = origFilter
+	defer origFilter.Stop()
+
+	bot.SetReloadFunc(func() (*Config, error) { return nil, errors.New("config source unavailable") })
+
+	got := bot.cmdReload()
+	if !strings.Contains(got, "Reload failed") {
+		t.Fatalf("cmdReload() = %q, want a reload-failed message", got)
+	}
+
+	if bot.conf != origConf {
+		t.Error("expected b.conf to be left untouched after a failed reload")
+	}
+	if len(bot.notifiers) != len(origNotifiers) {
+		t.Error("expected b.notifiers to be left untouched after a failed reload")
+	}
+	if bot.subFilter != origFilter {
+		t.Error("expected b.subFilter to be left untouched after a failed reload")
+	}
+}
+
+func TestCommandText(t *testing.T) {
+	cases := []struct {
+		text, target, botName string
+		wantText              string
+		wantOK                bool
+	}{
+		{"watch bob", "notifybot", "notifybot", "watch bob", true},
+		{"!watch bob", "#watchers", "notifybot", "watch bob", true},
+		{"just chatting", "#watchers", "notifybot", "", false},
+	}
+	for _, c := range cases {
+		got, ok := commandText(c.text, c.target, c.botName)
+		if ok != c.wantOK || got != c.wantText {
+			t.Errorf("commandText(%q, %q, %q) = (%q, %v), want (%q, %v)",
+				c.text, c.target, c.botName, got, ok, c.wantText, c.wantOK)
+		}
+	}
+}
@@ -2,60 +2,145 @@ package bot
 
 import (
 	"bufio"
+	"context"
+	"crypto/tls"
+	"encoding/base64"
 	"fmt"
 	"log/slog"
 	"net"
 	"strings"
+	"sync"
 	"time"
 
-	"github.com/aws/aws-sdk-go/aws"
-	"github.com/aws/aws-sdk-go/aws/session"
-	"github.com/aws/aws-sdk-go/service/ses"
 	"golang.org/x/exp/slices"
+
+	"notifybot/internal/backoff"
+	"notifybot/internal/irc"
 )
 
 const notifyBotVersion = "v0.3c"
 
 type Config struct {
-	Server      string
-	Port        string
-	BotName     string
-	Channels    []string
-	NotifyEmail string
-	FromEmail   string
-	SleepMin    string
-	AwsRegion   string
+	Server             string
+	Port               string
+	BotName            string
+	Channels           []string
+	SleepMin           string
+	TLS                bool   `yaml:"tls"`
+	InsecureSkipVerify bool   `yaml:"insecure_skip_verify"`
+	SASLUser           string `yaml:"sasl_user"`
+	SASLPass           string `yaml:"sasl_pass"`
+	// Admins is the allow-list of nick!user@host masks (wildcards allowed
+	// via "*") permitted to issue admin commands. See matchesMask: a bare
+	// nickname with no "!" is not accepted, since it can't verify user@host.
+	Admins         []string         `yaml:"admins"`
+	Notifiers      []NotifierConfig `yaml:"notifiers"`
+	StateStore     StateStoreConfig `yaml:"state_store"`
+	ColdStartGrace time.Duration    `yaml:"cold_start_grace"`
+	// PingTimeout is how long the bot will wait without seeing a server
+	// PING before assuming the connection is dead and forcing a reconnect.
+	// Defaults to 4 minutes.
+	PingTimeout time.Duration `yaml:"ping_timeout"`
+	// Subscriptions routes presence events to specific notifiers with
+	// their own filtering, rate limiting and quiet hours. If empty, every
+	// event goes to every configured notifier (the pre-Subscriptions
+	// behavior).
+	Subscriptions []Subscription `yaml:"subscriptions"`
 }
 
-// Mock SES client implementing only the SendEmail method needed for testing
-// Use an interface for sesClient to allow mocking
+const (
+	defaultColdStartGrace = 2 * time.Minute
+	defaultPingTimeout    = 4 * time.Minute
 
-type sesSender interface {
-	SendEmail(input *ses.SendEmailInput) (*ses.SendEmailOutput, error)
-}
+	// defaultSASLTimeout bounds how long negotiateSASL will wait for the
+	// handshake to complete. Without it, a server that advertises "sasl"
+	// in CAP LS but never actually answers AUTHENTICATE with 903/904/905
+	// would leave negotiateSASL owning the shared scanner forever, which
+	// silently swallows every later line including PINGs.
+	defaultSASLTimeout = 15 * time.Second
+)
 
 type NotifyBot struct {
-	conf          *Config
-	nicknames     map[string]bool
-	log           *slog.Logger
-	sleepDuration time.Duration
-	sesClient     sesSender
-	connected     bool
+	conf           *Config
+	log            *slog.Logger
+	sleepDuration  time.Duration
+	notifiers      []Notifier
+	connected      bool
+	stateStore     StateStore
+	bootTime       time.Time
+	coldStartGrace time.Duration
+	pingTimeout    time.Duration
+	saslTimeout    time.Duration
+	reloadFunc     func() (*Config, error)
+
+	// connectAttempts counts consecutive failed (re)connect attempts, reset
+	// to 0 on success. It's only touched from the Run() goroutine.
+	connectAttempts int
+
+	// mu guards nicknames, channels, conf, notifiers and subFilter, which
+	// runtime admin commands (see handleCommand, cmdReload) mutate while
+	// the ISON polling goroutine started by Run() reads nicknames.
+	mu        sync.Mutex
+	nicknames map[string]bool
+	channels  []string
+	subFilter *subscriptionFilter
+
+	// pingMu guards lastPing, which the Run() read loop updates on every
+	// server PING and the keepalive goroutine reads concurrently.
+	pingMu   sync.Mutex
+	lastPing time.Time
+}
+
+// SetReloadFunc wires up the function the admin `reload` command calls to
+// fetch fresh configuration. Typically this is the same function main()
+// used to build the initial Config.
+func (b *NotifyBot) SetReloadFunc(fn func() (*Config, error)) {
+	b.reloadFunc = fn
 }
 
 func NewNotifyBot(config *Config, log *slog.Logger, nicknames map[string]bool) *NotifyBot {
 	log.Info("NotifyBot starting", "version", notifyBotVersion)
+
+	notifiers, err := buildNotifiers(config.Notifiers, log)
+	if err != nil {
+		log.Error("Failed to build notifiers", "error", err)
+	}
+
+	var subFilter *subscriptionFilter
+	if len(config.Subscriptions) > 0 {
+		subFilter = newSubscriptionFilter(config.Subscriptions, notifiersByName(config.Notifiers, notifiers), log)
+	}
+
+	stateStore, err := buildStateStore(config.StateStore)
+	if err != nil {
+		log.Error("Failed to open state store", "error", err)
+	}
+	if stateStore != nil {
+		if states, err := stateStore.Load(); err != nil {
+			log.Error("Failed to load persisted nickname state", "error", err)
+		} else {
+			for nick := range nicknames {
+				if s, ok := states[nick]; ok {
+					nicknames[nick] = s.Online
+				}
+			}
+		}
+	}
+
 	var nicks []string
 	for k := range nicknames {
 		nicks = append(nicks, k)
 	}
 	log.Info("Notifying on nicknames", "nicknames", nicks)
 
-	sess, err := session.NewSession(&aws.Config{
-		Region: aws.String(config.AwsRegion),
-	})
-	if err != nil {
-		log.Error("Failed to create AWS session", "error", err)
+	coldStartGrace := config.ColdStartGrace
+	if coldStartGrace == 0 {
+		coldStartGrace = defaultColdStartGrace
+	}
+
+	pingTimeout := config.PingTimeout
+	if pingTimeout == 0 {
+		pingTimeout = defaultPingTimeout
 	}
 
 	return &NotifyBot{
@@ -74,8 +159,15 @@ func NewNotifyBot(config *Config, log *slog.Logger, nicknames map[string]bool) *
 			log.Error("'SleepMin' not provided in config, defaulting to 5 minutes")
 			return 5 * time.Minute // Default to 5 minutes if not provided
 		}(),
-		sesClient: ses.New(sess), // SES client for sending emails
-		connected: false,
+		notifiers:      notifiers,
+		subFilter:      subFilter,
+		connected:      false,
+		stateStore:     stateStore,
+		bootTime:       time.Now(),
+		coldStartGrace: coldStartGrace,
+		pingTimeout:    pingTimeout,
+		saslTimeout:    defaultSASLTimeout,
+		channels:       config.Channels,
 	}
 }
 
@@ -86,8 +178,19 @@ func (b *NotifyBot) setNickname(conn net.Conn) {
 }
 
 func (b *NotifyBot) connect() (net.Conn, error) {
-	b.log.Info("Attempting to connect to server", "server", b.conf.Server)
-	conn, err := net.Dial("tcp", net.JoinHostPort(b.conf.Server, b.conf.Port))
+	b.log.Info("Attempting to connect to server", "server", b.conf.Server, "tls", b.conf.TLS)
+	addr := net.JoinHostPort(b.conf.Server, b.conf.Port)
+
+	var conn net.Conn
+	var err error
+	if b.conf.TLS {
+		conn, err = tls.Dial("tcp", addr, &tls.Config{
+			ServerName:         b.conf.Server,
+			InsecureSkipVerify: b.conf.InsecureSkipVerify,
+		})
+	} else {
+		conn, err = net.Dial("tcp", addr)
+	}
 	if err != nil {
 		b.log.Error("Error connecting to server", "error", err)
 		return nil, err
@@ -95,83 +198,237 @@ func (b *NotifyBot) connect() (net.Conn, error) {
 	return conn, nil
 }
 
+// reconnect retries the connection with exponential backoff and full jitter
+// (base 2s, factor 2, capped at 5 minutes), so a flapping network doesn't
+// either hammer the server or leave the bot stuck sleeping for minutes once
+// the server comes back. connectAttempts is logged on every attempt so
+// operators can spot flapping from the logs alone.
 func (b *NotifyBot) reconnect() net.Conn {
 	for {
-		b.log.Info("Attempting to reconnect to server", "server", b.conf.Server)
+		b.connectAttempts++
+		b.log.Info("Attempting to reconnect to server", "server", b.conf.Server, "attempt", b.connectAttempts)
 		conn, err := b.connect()
 		if err == nil {
-			b.log.Info("Reconnected to server successfully", "server", b.conf.Server)
+			b.log.Info("Reconnected to server successfully", "server", b.conf.Server, "attempts", b.connectAttempts)
+			b.connectAttempts = 0
 			return conn
 		}
-		b.log.Error("Reconnection attempt failed", "error", err)
-		time.Sleep(3 * time.Minute) // Wait 3 minutes before retrying
+		delay := backoff.Default.Next(b.connectAttempts)
+		b.log.Error("Reconnection attempt failed", "error", err, "attempt", b.connectAttempts, "retry_in", delay)
+		time.Sleep(delay)
 	}
 }
 
-func (b *NotifyBot) handleISONResponse(parts []string) {
-	// Remove the leading colon and split nicknames, trim whitespace
-	isonField := strings.TrimPrefix(parts[3], ":")
-	isonField = strings.TrimSpace(isonField)
+// keepalive forces conn closed if no server PING arrives within
+// b.pingTimeout, so a half-open socket that never sends or receives data
+// doesn't go unnoticed. It returns once done is closed (the caller is
+// moving on to a new connection) or it closes conn itself.
+func (b *NotifyBot) keepalive(conn net.Conn, done <-chan struct{}) {
+	ticker := time.NewTicker(b.pingTimeout / 4)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-done:
+			return
+		case <-ticker.C:
+			b.pingMu.Lock()
+			sincePing := time.Since(b.lastPing)
+			b.pingMu.Unlock()
+
+			if sincePing > b.pingTimeout {
+				b.log.Error("No PING received within timeout, forcing reconnect", "timeout", b.pingTimeout, "since_last_ping", sincePing)
+				conn.Close()
+				return
+			}
+		}
+	}
+}
+
+// nickTransition records a single online/offline flip found while scanning
+// b.nicknames, to be acted on after b.mu is released.
+type nickTransition struct {
+	nickname string
+	online   bool
+}
+
+// handleISONResponse processes the trailing parameter of a 303 (RPL_ISON)
+// reply: a space-separated list of the requested nicknames that are
+// currently online. It updates b.nicknames from that list, then persists
+// and notifies on whatever changed. Persisting state and notifying both do
+// I/O (disk and, for notify, potentially slow retrying network calls), so
+// the state scan only holds b.mu long enough to read and mutate
+// b.nicknames; the ISON polling goroutine, which locks the same mutex to
+// read the watch list, would otherwise stall for however long notification
+// delivery takes.
+func (b *NotifyBot) handleISONResponse(isonField string) {
 	var currentnicknames []string
+	isonField = strings.TrimSpace(isonField)
 	if isonField != "" {
 		for _, n := range strings.Fields(isonField) {
 			currentnicknames = append(currentnicknames, strings.TrimSpace(n))
 		}
 	}
 
+	coldStart := time.Since(b.bootTime) < b.coldStartGrace
+
+	var toPersist, toNotify []nickTransition
+
+	b.mu.Lock()
+	notifiers := b.notifiers
+	subFilter := b.subFilter
 	for nickname := range b.nicknames {
 		isOnline := slices.Contains(currentnicknames, nickname)
 		if isOnline && !b.nicknames[nickname] {
-			b.log.Info("The following friend is now online:", "nickname", strings.TrimSuffix(nickname, "\n"))
 			b.nicknames[nickname] = true
-			b.notify(fmt.Sprintf("%s is online", nickname))
+			toPersist = append(toPersist, nickTransition{nickname, true})
+			if coldStart {
+				b.log.Info("Cold start: recording online state without notifying", "nickname", nickname)
+				continue
+			}
+			b.log.Info("The following friend is now online:", "nickname", strings.TrimSuffix(nickname, "\n"))
+			toNotify = append(toNotify, nickTransition{nickname, true})
 		} else if !isOnline && b.nicknames[nickname] {
-			b.log.Info("The following friend is now offline:", "nickname", strings.TrimSuffix(nickname, "\n"))
 			b.nicknames[nickname] = false
-			b.notify(fmt.Sprintf("%s is offline", nickname))
+			toPersist = append(toPersist, nickTransition{nickname, false})
+			if coldStart {
+				b.log.Info("Cold start: recording offline state without notifying", "nickname", nickname)
+				continue
+			}
+			b.log.Info("The following friend is now offline:", "nickname", strings.TrimSuffix(nickname, "\n"))
+			toNotify = append(toNotify, nickTransition{nickname, false})
+		}
+	}
+	b.mu.Unlock()
+
+	for _, t := range toPersist {
+		b.persistState(t.nickname, t.online)
+	}
+	for _, t := range toNotify {
+		message := fmt.Sprintf("%s is online", t.nickname)
+		if !t.online {
+			message = fmt.Sprintf("%s is offline", t.nickname)
 		}
+		b.notify(notifiers, subFilter, t.nickname, t.online, message)
 	}
 }
 
-func (b *NotifyBot) notify(msg string) {
-	subject := "IRC Notification Event"
-
-	// Use UTC for timestamp
-	timestamp := time.Now().UTC().Format("2006-01-02 15:04:05 UTC")
-
-	// Append the timestamp to the message
-	msg = fmt.Sprintf("[%s] %s", timestamp, msg)
-
-	// Construct email input
-	input := &ses.SendEmailInput{
-		Destination: &ses.Destination{
-			ToAddresses: []*string{
-				aws.String(b.conf.NotifyEmail),
-			},
-		},
-		Message: &ses.Message{
-			Body: &ses.Body{
-				Text: &ses.Content{
-					Charset: aws.String("UTF-8"),
-					Data:    aws.String(msg),
-				},
-			},
-			Subject: &ses.Content{
-				Charset: aws.String("UTF-8"),
-				Data:    aws.String(subject),
-			},
-		},
-		Source: aws.String(b.conf.FromEmail),
+// persistState writes a presence transition to the configured state store,
+// if any, so a restart doesn't mistake the last known state for "offline".
+func (b *NotifyBot) persistState(nickname string, online bool) {
+	if b.stateStore == nil {
+		return
+	}
+	if err := b.stateStore.Save(nickname, online, time.Now().UTC()); err != nil {
+		b.log.Error("Failed to persist nickname state", "nickname", nickname, "error", err)
 	}
+}
 
-	// Send the email
-	_, err := b.sesClient.SendEmail(input)
-	if err != nil {
-		b.log.Error("Error sending email", "error", err)
+// notify fans event out to either subFilter (if configured) or every
+// notifier in notifiers, each with its own retry policy; a failure on one
+// notifier doesn't stop delivery to the rest. notifiers and subFilter are
+// passed in rather than read from b.notifiers/b.subFilter directly, so
+// callers can snapshot them under b.mu and then call notify without
+// holding it: notify does potentially slow, retrying network I/O, and
+// must not be called with b.mu held.
+func (b *NotifyBot) notify(notifiers []Notifier, subFilter *subscriptionFilter, nickname string, online bool, message string) {
+	event := Event{
+		Nickname:  nickname,
+		Online:    online,
+		Message:   message,
+		Timestamp: time.Now().UTC(),
+	}
+
+	if subFilter != nil {
+		subFilter.dispatch(event)
 		return
 	}
 
-	b.log.Info("Email sent successfully", "recipient", b.conf.NotifyEmail)
+	for _, n := range notifiers {
+		if err := n.Notify(context.Background(), event); err != nil {
+			b.log.Error("Error delivering notification", "error", err)
+			continue
+		}
+		b.log.Info("Notification delivered successfully")
+	}
+}
+
+// negotiateSASL drives the IRCv3 CAP / SASL PLAIN handshake: CAP LS, CAP REQ
+// :sasl, AUTHENTICATE PLAIN with base64 "\0user\0pass", then CAP END. It
+// shares scanner with the caller's main read loop so no bytes are lost
+// between the handshake and normal message dispatch. The whole handshake
+// is bounded by b.saslTimeout so a server that never completes it doesn't
+// stall the connection indefinitely.
+func (b *NotifyBot) negotiateSASL(conn net.Conn, scanner *bufio.Scanner) error {
+	timeout := b.saslTimeout
+	if timeout <= 0 {
+		timeout = defaultSASLTimeout
+	}
+	conn.SetReadDeadline(time.Now().Add(timeout))
+	defer conn.SetReadDeadline(time.Time{})
+
+	for scanner.Scan() {
+		line := scanner.Text()
+		b.log.Info(line)
+
+		msg, err := irc.Parse(line)
+		if err != nil {
+			continue
+		}
+
+		switch msg.Command {
+		case "CAP":
+			if len(msg.Params) < 2 {
+				continue
+			}
+			switch msg.Params[1] {
+			case "LS":
+				fmt.Fprintf(conn, "CAP REQ :sasl\r\n")
+			case "ACK":
+				if strings.Contains(msg.Trailing, "sasl") {
+					fmt.Fprintf(conn, "AUTHENTICATE PLAIN\r\n")
+				}
+			case "NAK":
+				fmt.Fprintf(conn, "CAP END\r\n")
+				return fmt.Errorf("server rejected sasl capability request")
+			}
+		case "AUTHENTICATE":
+			if len(msg.Params) > 0 && msg.Params[0] == "+" {
+				payload := fmt.Sprintf("\x00%s\x00%s", b.conf.SASLUser, b.conf.SASLPass)
+				encoded := base64.StdEncoding.EncodeToString([]byte(payload))
+				fmt.Fprintf(conn, "AUTHENTICATE %s\r\n", encoded)
+			}
+		case "903": // RPL_SASLSUCCESS
+			b.log.Info("SASL authentication succeeded")
+			fmt.Fprintf(conn, "CAP END\r\n")
+			return nil
+		case "904", "905": // ERR_SASLFAIL / ERR_SASLTOOLONG
+			fmt.Fprintf(conn, "CAP END\r\n")
+			return fmt.Errorf("sasl authentication failed: %s", msg.Trailing)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("sasl negotiation: %w", err)
+	}
+	return fmt.Errorf("sasl negotiation ended before completion (connection closed)")
+}
+
+func (b *NotifyBot) handlePRIVMSG(conn net.Conn, msg *irc.Message) {
+	if len(msg.Params) == 0 {
+		return
+	}
+	target := msg.Params[0]
+
+	if strings.Contains(msg.Trailing, "VERSION") {
+		nickname := msg.Nick()
+		fmt.Fprintf(conn, "NOTICE %s :NotifyBot %s\r\n", nickname, notifyBotVersion)
+		b.log.Info("Version request acknowledged", "nickname", nickname, "version", notifyBotVersion)
+		return
+	}
+
+	if cmdText, ok := commandText(msg.Trailing, target, b.conf.BotName); ok {
+		b.handleCommand(conn, msg.Prefix, cmdText)
+	}
 }
 
 func (b *NotifyBot) Run() {
@@ -182,66 +439,98 @@ reconnectLoop:
 			b.log.Error("Failed to connect to server", "error", err)
 			conn = b.reconnect() // Attempt to reconnect if the initial connection fails
 		}
-		b.setNickname(conn)
 
-		// read incoming messages from the server and act on them
+		b.pingMu.Lock()
+		b.lastPing = time.Now()
+		b.pingMu.Unlock()
+
+		done := make(chan struct{})
+		go b.keepalive(conn, done)
+
 		scanner := bufio.NewScanner(conn)
-		for scanner.Scan() {
-			msg := scanner.Text()
-			b.log.Info(msg)
-			parts := strings.Split(msg, " ")
-
-			if len(parts) > 0 {
-				switch {
-				case parts[0] == "PING":
-					fmt.Fprintf(conn, "PONG %s\r\n", parts[1])
-					b.log.Info("PONG", "id", parts[1])
-				case parts[1] == "303": // ISON response
-					if len(parts) > 3 {
-						b.handleISONResponse(parts)
-					}
-				case parts[1] == "433": // Nickname in use
-					b.log.Error("Nickname is already in use. Appending _ to the end of the nick.", "nickname", b.conf.BotName)
-					b.conf.BotName = fmt.Sprintf("%s_", b.conf.BotName)
-					b.setNickname(conn)
-				case parts[1] == "PRIVMSG":
-					if strings.Contains((parts[3]), "VERSION") {
-						nickname := strings.TrimPrefix(parts[0], ":")
-						nickname = strings.Split(nickname, "!")[0]
-						fmt.Fprintf(conn, "NOTICE %s :NotifyBot %s\r\n", nickname, notifyBotVersion)
-						b.log.Info("Version request acknowledged", "nickname", nickname, "version", notifyBotVersion)
-					}
-				case parts[0] == "ERROR":
-					b.log.Error("Server error, attempting to reconnect", "error", msg)
-					conn.Close()
-					continue reconnectLoop // Go back to the top and reconnect
-				case strings.Contains(msg, fmt.Sprintf("NOTICE %s :on", b.conf.BotName)):
+
+		if b.conf.SASLUser != "" {
+			fmt.Fprintf(conn, "CAP LS 302\r\n")
+		}
+		b.setNickname(conn)
+		if b.conf.SASLUser != "" {
+			if err := b.negotiateSASL(conn, scanner); err != nil {
+				b.log.Error("SASL negotiation failed", "error", err)
+			}
+		}
+
+		// read incoming messages from the server and act on them. The read
+		// deadline is renewed before every Scan so a peer that disappears
+		// without closing the socket doesn't leave Scan() blocked forever.
+		for {
+			conn.SetReadDeadline(time.Now().Add(b.pingTimeout))
+			if !scanner.Scan() {
+				break
+			}
+			line := scanner.Text()
+			b.log.Info(line)
+
+			msg, err := irc.Parse(line)
+			if err != nil {
+				b.log.Error("Failed to parse IRC message", "line", line, "error", err)
+				continue
+			}
+
+			switch msg.Command {
+			case "PING":
+				b.pingMu.Lock()
+				b.lastPing = time.Now()
+				b.pingMu.Unlock()
+				fmt.Fprintf(conn, "PONG :%s\r\n", msg.Trailing)
+				b.log.Info("PONG", "id", msg.Trailing)
+			case "303": // RPL_ISON
+				b.handleISONResponse(msg.Trailing)
+			case "433": // ERR_NICKNAMEINUSE
+				b.log.Error("Nickname is already in use. Appending _ to the end of the nick.", "nickname", b.conf.BotName)
+				b.conf.BotName = fmt.Sprintf("%s_", b.conf.BotName)
+				b.setNickname(conn)
+			case "PRIVMSG":
+				b.handlePRIVMSG(conn, msg)
+			case "ERROR":
+				b.log.Error("Server error, attempting to reconnect", "error", line)
+				close(done)
+				conn.Close()
+				continue reconnectLoop // Go back to the top and reconnect
+			case "NOTICE":
+				if len(msg.Params) > 0 && msg.Params[0] == b.conf.BotName && strings.HasPrefix(msg.Trailing, "on") {
 					b.log.Info("Connected to server", "server", b.conf.Server)
 					b.connected = true
 
 					// join any channels specified in the config
-					if b.conf.Channels[0] != "" {
-						for _, channel := range b.conf.Channels {
+					b.mu.Lock()
+					channels := append([]string(nil), b.channels...)
+					b.mu.Unlock()
+					if len(channels) > 0 && channels[0] != "" {
+						for _, channel := range channels {
 							fmt.Fprintf(conn, "JOIN %s\r\n", channel)
 						}
 					}
 
-					// Check who is online every X configured minutes
-					var keys []string
-					for k := range b.nicknames {
-						keys = append(keys, k)
-					}
-					nicknames := strings.Join(keys, " ")
-
-					go func(c net.Conn, nicks string, sleep time.Duration) {
+					// Check who is online every X configured minutes. The
+					// watch list is re-read from b.nicknames on every tick
+					// so runtime `watch`/`unwatch` commands take effect
+					// without needing a reconnect.
+					go func(c net.Conn, sleep time.Duration) {
 						for {
-							_, err := fmt.Fprintf(c, "ISON %s\r\n", nicks)
+							b.mu.Lock()
+							var keys []string
+							for k := range b.nicknames {
+								keys = append(keys, k)
+							}
+							b.mu.Unlock()
+
+							_, err := fmt.Fprintf(c, "ISON %s\r\n", strings.Join(keys, " "))
 							if err != nil {
 								return // exit goroutine if write fails
 							}
 							time.Sleep(sleep)
 						}
-					}(conn, nicknames, b.sleepDuration)
+					}(conn, b.sleepDuration)
 				}
 			}
 		}
@@ -252,6 +541,7 @@ reconnectLoop:
 		} else {
 			b.log.Error("Disconnected from server, attempting to reconnect")
 		}
+		close(done)
 		conn.Close()
 		// Loop will restart and reconnect
 	}
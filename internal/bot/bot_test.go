@@ -2,23 +2,22 @@ package bot
 
 import (
 	"bytes"
+	"context"
 	"log/slog"
 	"net"
+	"path/filepath"
 	"testing"
 	"time"
-
-	"github.com/aws/aws-sdk-go/service/ses"
 )
 
-// Mock SES client implementing only the SendEmail method needed for testing
-type mockSES struct {
-	ses.SES
-	sent bool
+// mockNotifier records every Event it receives for assertions in tests.
+type mockNotifier struct {
+	events []Event
 }
 
-func (m *mockSES) SendEmail(input *ses.SendEmailInput) (*ses.SendEmailOutput, error) {
-	m.sent = true
-	return &ses.SendEmailOutput{}, nil
+func (m *mockNotifier) Notify(ctx context.Context, event Event) error {
+	m.events = append(m.events, event)
+	return nil
 }
 
 // Dummy net.Conn for testing
@@ -36,74 +35,120 @@ func (d *dummyConn) SetReadDeadline(t time.Time) error  { return nil }
 func (d *dummyConn) SetWriteDeadline(t time.Time) error { return nil }
 
 func TestNotifyBot_notify(t *testing.T) {
-	conf := &Config{
-		NotifyEmail: "to@example.com",
-		FromEmail:   "from@example.com",
-		AwsRegion:   "us-east-1",
-	}
+	conf := &Config{ColdStartGrace: time.Nanosecond}
 	nicknames := map[string]bool{"alice": false}
 	log := slog.New(slog.NewTextHandler(&bytes.Buffer{}, nil))
 	bot := NewNotifyBot(conf, log, nicknames)
-	mock := &mockSES{}
-	bot.sesClient = mock // now valid, as sesClient is an interface
+	mock := &mockNotifier{}
+	bot.notifiers = []Notifier{mock}
+
+	bot.notify(bot.notifiers, bot.subFilter, "alice", true, "test message")
+	if len(mock.events) != 1 {
+		t.Fatalf("expected 1 notification, got %d", len(mock.events))
+	}
+	if mock.events[0].Message != "test message" {
+		t.Errorf("unexpected message: %q", mock.events[0].Message)
+	}
+}
 
-	bot.notify("test message")
-	if !mock.sent {
-		t.Error("Expected SES SendEmail to be called")
+// eventFor returns the event recorded for nickname, if any.
+func eventFor(events []Event, nickname string) *Event {
+	for i := range events {
+		if events[i].Nickname == nickname {
+			return &events[i]
+		}
 	}
+	return nil
 }
 
 func TestHandleISONResponse_online_offline(t *testing.T) {
-	conf := &Config{}
+	conf := &Config{ColdStartGrace: time.Nanosecond}
 	nicknames := map[string]bool{"alice": false, "bob": true, "marlene": false}
 	log := slog.New(slog.NewTextHandler(&bytes.Buffer{}, nil))
 	bot := NewNotifyBot(conf, log, nicknames)
-	mock := &mockSES{}
-	bot.sesClient = mock
+	mock := &mockNotifier{}
+	bot.notifiers = []Notifier{mock}
 
-	// alice comes online
-	parts := []string{"", "303", "notifybot", ":alice"}
-	bot.handleISONResponse(parts)
+	// alice comes online (bob implicitly drops off the ISON list too, so it
+	// also flips to offline in this pass)
+	bot.handleISONResponse("alice")
 	if !bot.nicknames["alice"] {
 		t.Error("alice should be marked online")
 	}
-	if !mock.sent {
-		t.Error("Expected SES SendEmail to be called for alice online")
+	if e := eventFor(mock.events, "alice"); e == nil || !e.Online {
+		t.Fatal("Expected an online notification to be delivered for alice")
 	}
-	mock.sent = false // reset
+	mock.events = nil
 
 	// marlene comes online with trailing space
-	parts = []string{"", "303", "notifybot", ":marlene "}
-	bot.handleISONResponse(parts)
+	bot.handleISONResponse("marlene ")
 	if !bot.nicknames["marlene"] {
 		t.Error("marlene should be marked online")
 	}
-	if !mock.sent {
-		t.Error("Expected SES SendEmail to be called for marlene online")
+	if e := eventFor(mock.events, "marlene"); e == nil || !e.Online {
+		t.Fatal("Expected an online notification to be delivered for marlene")
 	}
-	mock.sent = false // reset
+	mock.events = nil
 
-	// marlene goes offline (empty ISON response)
-	parts = []string{"", "303", "notifybot", ":"}
-	bot.handleISONResponse(parts)
+	// marlene goes offline (empty ISON response); alice already went
+	// offline in the previous pass, since "marlene " did not list her
+	bot.handleISONResponse("")
 	if bot.nicknames["marlene"] {
 		t.Error("marlene should be marked offline")
 	}
-	if !mock.sent {
-		t.Error("Expected SES SendEmail to be called for marlene offline")
+	if e := eventFor(mock.events, "marlene"); e == nil || e.Online {
+		t.Fatal("Expected an offline notification to be delivered for marlene")
 	}
-	mock.sent = false // reset
+	mock.events = nil
 
-	// bob goes offline (was true at start)
-	// Only notify if state changes from true to false
-	// Since bob was set offline above, no notification should be sent again
-	parts = []string{"", "303", "notifybot", ":"}
-	bot.handleISONResponse(parts)
+	// bob is already offline from the first pass; an empty ISON response
+	// again should not re-trigger a notification for it (no state change)
+	bot.handleISONResponse("")
 	if bot.nicknames["bob"] {
 		t.Error("bob should be marked offline")
 	}
-	if mock.sent {
-		t.Error("SES SendEmail should NOT be called for bob offline again (no state change)")
+	if e := eventFor(mock.events, "bob"); e != nil {
+		t.Error("No notification should be delivered for bob offline again (no state change)")
+	}
+}
+
+func TestNewNotifyBot_hydratesFromStateStore(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state.json")
+	store := newJSONStateStore(path)
+	if err := store.Save("alice", true, time.Now().UTC()); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+
+	conf := &Config{
+		ColdStartGrace: time.Nanosecond,
+		StateStore:     StateStoreConfig{Type: "json", Path: path},
+	}
+	log := slog.New(slog.NewTextHandler(&bytes.Buffer{}, nil))
+	bot := NewNotifyBot(conf, log, map[string]bool{"alice": false, "bob": false})
+
+	if !bot.nicknames["alice"] {
+		t.Error("expected alice's persisted online state to be restored on startup")
+	}
+	if bot.nicknames["bob"] {
+		t.Error("bob has no persisted state and should default to offline")
+	}
+}
+
+func TestHandleISONResponse_coldStartSuppressesNotify(t *testing.T) {
+	conf := &Config{ColdStartGrace: time.Hour}
+	nicknames := map[string]bool{"alice": false}
+	log := slog.New(slog.NewTextHandler(&bytes.Buffer{}, nil))
+	bot := NewNotifyBot(conf, log, nicknames)
+	mock := &mockNotifier{}
+	bot.notifiers = []Notifier{mock}
+
+	bot.handleISONResponse("alice")
+
+	if !bot.nicknames["alice"] {
+		t.Error("alice should still be marked online during the grace period")
+	}
+	if len(mock.events) != 0 {
+		t.Error("no notification should be delivered during the cold start grace period")
 	}
 }
 
@@ -0,0 +1,157 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+)
+
+// Event describes a single presence change to be delivered by a Notifier.
+type Event struct {
+	Nickname  string
+	Online    bool
+	Message   string
+	Timestamp time.Time
+}
+
+// Notifier delivers presence Events to a single sink (email, webhook, chat, ...).
+type Notifier interface {
+	Notify(ctx context.Context, event Event) error
+}
+
+// NotifierConfig is one stanza of the `notifiers` list, either loaded from
+// YAML or assembled by hand. Exactly one of the backend-specific fields
+// should be set, matching Type.
+type NotifierConfig struct {
+	Type string `yaml:"type"`
+
+	// Name identifies this notifier instance for Subscription.Recipient to
+	// route to. Defaults to Type, which is fine as long as there's only
+	// one notifier of a given type.
+	Name string `yaml:"name"`
+
+	MaxRetries int           `yaml:"max_retries"`
+	RetryDelay time.Duration `yaml:"retry_delay"`
+
+	SES     *SESNotifierConfig     `yaml:"ses,omitempty"`
+	SMTP    *SMTPNotifierConfig    `yaml:"smtp,omitempty"`
+	Webhook *WebhookNotifierConfig `yaml:"webhook,omitempty"`
+	XMPP    *XMPPNotifierConfig    `yaml:"xmpp,omitempty"`
+	Matrix  *MatrixNotifierConfig  `yaml:"matrix,omitempty"`
+}
+
+const (
+	defaultMaxRetries = 2
+	defaultRetryDelay = 5 * time.Second
+)
+
+// buildNotifiers turns the configured notifier stanzas into live Notifiers,
+// each wrapped with the per-notifier retry policy from its stanza.
+func buildNotifiers(configs []NotifierConfig, log *slog.Logger) ([]Notifier, error) {
+	notifiers := make([]Notifier, 0, len(configs))
+	for _, cfg := range configs {
+		n, err := buildNotifier(cfg, log)
+		if err != nil {
+			return nil, fmt.Errorf("notifier %q: %w", cfg.Type, err)
+		}
+
+		maxRetries := cfg.MaxRetries
+		if maxRetries == 0 {
+			maxRetries = defaultMaxRetries
+		}
+		retryDelay := cfg.RetryDelay
+		if retryDelay == 0 {
+			retryDelay = defaultRetryDelay
+		}
+
+		notifiers = append(notifiers, &retryingNotifier{
+			Notifier:   n,
+			name:       cfg.Type,
+			maxRetries: maxRetries,
+			retryDelay: retryDelay,
+			log:        log,
+		})
+	}
+	return notifiers, nil
+}
+
+// notifiersByName pairs each built notifier with the Name (or Type, if Name
+// is unset) of the config stanza that produced it, for Subscription-based
+// routing. It assumes notifiers was built from configs by buildNotifiers,
+// in the same order.
+func notifiersByName(configs []NotifierConfig, notifiers []Notifier) map[string]Notifier {
+	byName := make(map[string]Notifier, len(notifiers))
+	for i, cfg := range configs {
+		if i >= len(notifiers) {
+			break
+		}
+		name := cfg.Name
+		if name == "" {
+			name = cfg.Type
+		}
+		byName[name] = notifiers[i]
+	}
+	return byName
+}
+
+func buildNotifier(cfg NotifierConfig, log *slog.Logger) (Notifier, error) {
+	switch cfg.Type {
+	case "ses":
+		if cfg.SES == nil {
+			return nil, fmt.Errorf("missing ses config")
+		}
+		return newSESNotifier(cfg.SES, log)
+	case "smtp":
+		if cfg.SMTP == nil {
+			return nil, fmt.Errorf("missing smtp config")
+		}
+		return newSMTPNotifier(cfg.SMTP), nil
+	case "webhook":
+		if cfg.Webhook == nil {
+			return nil, fmt.Errorf("missing webhook config")
+		}
+		return newWebhookNotifier(cfg.Webhook), nil
+	case "xmpp":
+		if cfg.XMPP == nil {
+			return nil, fmt.Errorf("missing xmpp config")
+		}
+		return newXMPPNotifier(cfg.XMPP), nil
+	case "matrix":
+		if cfg.Matrix == nil {
+			return nil, fmt.Errorf("missing matrix config")
+		}
+		return newMatrixNotifier(cfg.Matrix), nil
+	default:
+		return nil, fmt.Errorf("unknown notifier type %q", cfg.Type)
+	}
+}
+
+// retryingNotifier wraps a Notifier with a fixed-delay retry policy so a
+// transient failure on one sink doesn't drop the event entirely.
+type retryingNotifier struct {
+	Notifier
+	name       string
+	maxRetries int
+	retryDelay time.Duration
+	log        *slog.Logger
+}
+
+func (r *retryingNotifier) Notify(ctx context.Context, event Event) error {
+	var err error
+	for attempt := 0; attempt <= r.maxRetries; attempt++ {
+		if attempt > 0 {
+			r.log.Info("retrying notifier", "notifier", r.name, "attempt", attempt)
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(r.retryDelay):
+			}
+		}
+		if err = r.Notifier.Notify(ctx, event); err == nil {
+			return nil
+		}
+		r.log.Error("notifier delivery failed", "notifier", r.name, "attempt", attempt, "error", err)
+	}
+	return fmt.Errorf("notifier %q: %w", r.name, err)
+}
@@ -0,0 +1,64 @@
+package bot
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// MatrixNotifierConfig configures delivery as a message sent via the Matrix
+// client-server HTTP API.
+type MatrixNotifierConfig struct {
+	HomeserverURL string `yaml:"homeserver_url"`
+	AccessToken   string `yaml:"access_token"`
+	RoomID        string `yaml:"room_id"`
+}
+
+type matrixNotifier struct {
+	conf   *MatrixNotifierConfig
+	client *http.Client
+}
+
+func newMatrixNotifier(conf *MatrixNotifierConfig) *matrixNotifier {
+	return &matrixNotifier{conf: conf, client: &http.Client{Timeout: 10 * time.Second}}
+}
+
+type matrixMessage struct {
+	MsgType string `json:"msgtype"`
+	Body    string `json:"body"`
+}
+
+func (n *matrixNotifier) Notify(ctx context.Context, event Event) error {
+	body, err := json.Marshal(matrixMessage{
+		MsgType: "m.text",
+		Body:    fmt.Sprintf("[%s] %s", event.Timestamp.Format("2006-01-02 15:04:05 UTC"), event.Message),
+	})
+	if err != nil {
+		return fmt.Errorf("marshaling matrix message: %w", err)
+	}
+
+	txnID := event.Timestamp.UnixNano()
+	url := fmt.Sprintf("%s/_matrix/client/v3/rooms/%s/send/m.room.message/%d",
+		n.conf.HomeserverURL, n.conf.RoomID, txnID)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("building matrix request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+n.conf.AccessToken)
+
+	resp, err := n.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("posting to matrix: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("matrix API returned status %d", resp.StatusCode)
+	}
+	return nil
+}
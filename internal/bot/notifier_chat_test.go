@@ -0,0 +1,60 @@
+package bot
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestMatrixNotifier_notifySendsExpectedMessage(t *testing.T) {
+	var gotPath, gotAuth string
+	var received matrixMessage
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotPath = r.URL.Path
+		gotAuth = r.Header.Get("Authorization")
+		if err := json.NewDecoder(r.Body).Decode(&received); err != nil {
+			t.Errorf("decoding matrix message: %v", err)
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer srv.Close()
+
+	n := newMatrixNotifier(&MatrixNotifierConfig{
+		HomeserverURL: srv.URL,
+		AccessToken:   "tok123",
+		RoomID:        "!room:example.com",
+	})
+
+	event := Event{Nickname: "alice", Online: true, Message: "alice is online", Timestamp: time.Date(2026, 7, 26, 12, 0, 0, 0, time.UTC)}
+	if err := n.Notify(context.Background(), event); err != nil {
+		t.Fatalf("Notify returned an error: %v", err)
+	}
+
+	if gotAuth != "Bearer tok123" {
+		t.Errorf("Authorization header = %q, want %q", gotAuth, "Bearer tok123")
+	}
+	wantPathPrefix := "/_matrix/client/v3/rooms/!room:example.com/send/m.room.message/"
+	if len(gotPath) < len(wantPathPrefix) || gotPath[:len(wantPathPrefix)] != wantPathPrefix {
+		t.Errorf("request path = %q, want prefix %q", gotPath, wantPathPrefix)
+	}
+	if received.MsgType != "m.text" || received.Body == "" {
+		t.Errorf("unexpected matrix message: %+v", received)
+	}
+}
+
+func TestMatrixNotifier_notifyErrorsOnNonSuccessStatus(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+	}))
+	defer srv.Close()
+
+	n := newMatrixNotifier(&MatrixNotifierConfig{HomeserverURL: srv.URL, RoomID: "!room:example.com"})
+
+	err := n.Notify(context.Background(), Event{Message: "x", Timestamp: time.Now()})
+	if err == nil {
+		t.Fatal("expected an error for a 403 response")
+	}
+}
@@ -0,0 +1,69 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/ses"
+)
+
+// SESNotifierConfig configures delivery via AWS Simple Email Service.
+type SESNotifierConfig struct {
+	Region      string `yaml:"region"`
+	FromEmail   string `yaml:"from_email"`
+	NotifyEmail string `yaml:"notify_email"`
+}
+
+// sesSender is implemented by *ses.SES; narrowed to the one call we make so
+// it can be mocked in tests.
+type sesSender interface {
+	SendEmail(input *ses.SendEmailInput) (*ses.SendEmailOutput, error)
+}
+
+type sesNotifier struct {
+	conf   *SESNotifierConfig
+	client sesSender
+}
+
+func newSESNotifier(conf *SESNotifierConfig, log *slog.Logger) (*sesNotifier, error) {
+	sess, err := session.NewSession(&aws.Config{
+		Region: aws.String(conf.Region),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("creating AWS session: %w", err)
+	}
+	return &sesNotifier{conf: conf, client: ses.New(sess)}, nil
+}
+
+func (n *sesNotifier) Notify(ctx context.Context, event Event) error {
+	subject := "IRC Notification Event"
+	body := fmt.Sprintf("[%s] %s", event.Timestamp.Format("2006-01-02 15:04:05 UTC"), event.Message)
+
+	input := &ses.SendEmailInput{
+		Destination: &ses.Destination{
+			ToAddresses: []*string{aws.String(n.conf.NotifyEmail)},
+		},
+		Message: &ses.Message{
+			Body: &ses.Body{
+				Text: &ses.Content{
+					Charset: aws.String("UTF-8"),
+					Data:    aws.String(body),
+				},
+			},
+			Subject: &ses.Content{
+				Charset: aws.String("UTF-8"),
+				Data:    aws.String(subject),
+			},
+		},
+		Source: aws.String(n.conf.FromEmail),
+	}
+
+	_, err := n.client.SendEmail(input)
+	if err != nil {
+		return fmt.Errorf("sending email via SES: %w", err)
+	}
+	return nil
+}
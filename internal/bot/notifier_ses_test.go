@@ -0,0 +1,66 @@
+package bot
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/service/ses"
+)
+
+// fakeSESSender is a sesSender double that records the last input it was
+// called with and returns a configurable error.
+type fakeSESSender struct {
+	lastInput *ses.SendEmailInput
+	err       error
+}
+
+func (f *fakeSESSender) SendEmail(input *ses.SendEmailInput) (*ses.SendEmailOutput, error) {
+	f.lastInput = input
+	if f.err != nil {
+		return nil, f.err
+	}
+	return &ses.SendEmailOutput{}, nil
+}
+
+func TestSESNotifier_notifySendsExpectedEmail(t *testing.T) {
+	fake := &fakeSESSender{}
+	n := &sesNotifier{
+		conf:   &SESNotifierConfig{FromEmail: "notifybot@example.com", NotifyEmail: "watchers@example.com"},
+		client: fake,
+	}
+
+	event := Event{Nickname: "alice", Online: true, Message: "alice is online", Timestamp: time.Date(2026, 7, 26, 12, 0, 0, 0, time.UTC)}
+	if err := n.Notify(context.Background(), event); err != nil {
+		t.Fatalf("Notify returned an error: %v", err)
+	}
+
+	if fake.lastInput == nil {
+		t.Fatal("expected SendEmail to be called")
+	}
+	if got := *fake.lastInput.Source; got != "notifybot@example.com" {
+		t.Errorf("Source = %q, want %q", got, "notifybot@example.com")
+	}
+	if got := *fake.lastInput.Destination.ToAddresses[0]; got != "watchers@example.com" {
+		t.Errorf("ToAddresses[0] = %q, want %q", got, "watchers@example.com")
+	}
+	body := *fake.lastInput.Message.Body.Text.Data
+	if !strings.Contains(body, "alice is online") || !strings.Contains(body, "2026-07-26") {
+		t.Errorf("email body = %q, missing expected content", body)
+	}
+}
+
+func TestSESNotifier_notifyWrapsSendError(t *testing.T) {
+	fake := &fakeSESSender{err: errors.New("throttled")}
+	n := &sesNotifier{
+		conf:   &SESNotifierConfig{FromEmail: "notifybot@example.com", NotifyEmail: "watchers@example.com"},
+		client: fake,
+	}
+
+	err := n.Notify(context.Background(), Event{Message: "x", Timestamp: time.Now()})
+	if err == nil || !errors.Is(err, fake.err) {
+		t.Fatalf("expected Notify to wrap the send error, got: %v", err)
+	}
+}
@@ -0,0 +1,45 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+	"net/smtp"
+)
+
+// SMTPNotifierConfig configures delivery via a generic SMTP relay, for
+// operators who don't want to run this through AWS.
+type SMTPNotifierConfig struct {
+	Host        string `yaml:"host"`
+	Port        string `yaml:"port"`
+	Username    string `yaml:"username"`
+	Password    string `yaml:"password"`
+	FromEmail   string `yaml:"from_email"`
+	NotifyEmail string `yaml:"notify_email"`
+}
+
+type smtpNotifier struct {
+	conf *SMTPNotifierConfig
+}
+
+func newSMTPNotifier(conf *SMTPNotifierConfig) *smtpNotifier {
+	return &smtpNotifier{conf: conf}
+}
+
+func (n *smtpNotifier) Notify(ctx context.Context, event Event) error {
+	subject := "IRC Notification Event"
+	body := fmt.Sprintf("[%s] %s", event.Timestamp.Format("2006-01-02 15:04:05 UTC"), event.Message)
+
+	msg := fmt.Sprintf("From: %s\r\nTo: %s\r\nSubject: %s\r\n\r\n%s\r\n",
+		n.conf.FromEmail, n.conf.NotifyEmail, subject, body)
+
+	addr := fmt.Sprintf("%s:%s", n.conf.Host, n.conf.Port)
+	var auth smtp.Auth
+	if n.conf.Username != "" {
+		auth = smtp.PlainAuth("", n.conf.Username, n.conf.Password, n.conf.Host)
+	}
+
+	if err := smtp.SendMail(addr, auth, n.conf.FromEmail, []string{n.conf.NotifyEmail}, []byte(msg)); err != nil {
+		return fmt.Errorf("sending email via SMTP: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,88 @@
+package bot
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+// failThenSucceedNotifier fails its first `failures` calls, then succeeds.
+type failThenSucceedNotifier struct {
+	failures int
+	calls    int
+}
+
+func (f *failThenSucceedNotifier) Notify(ctx context.Context, event Event) error {
+	f.calls++
+	if f.calls <= f.failures {
+		return errors.New("transient failure")
+	}
+	return nil
+}
+
+func TestRetryingNotifier_succeedsAfterTransientFailures(t *testing.T) {
+	inner := &failThenSucceedNotifier{failures: 2}
+	r := &retryingNotifier{
+		Notifier:   inner,
+		name:       "test",
+		maxRetries: 3,
+		retryDelay: time.Millisecond,
+		log:        testLogger(),
+	}
+
+	if err := r.Notify(context.Background(), Event{Message: "x"}); err != nil {
+		t.Fatalf("Notify returned an error: %v", err)
+	}
+	if inner.calls != 3 {
+		t.Errorf("expected 3 calls (2 failures + 1 success), got %d", inner.calls)
+	}
+}
+
+func TestRetryingNotifier_givesUpAfterMaxRetries(t *testing.T) {
+	inner := &failThenSucceedNotifier{failures: 100}
+	r := &retryingNotifier{
+		Notifier:   inner,
+		name:       "test",
+		maxRetries: 2,
+		retryDelay: time.Millisecond,
+		log:        testLogger(),
+	}
+
+	err := r.Notify(context.Background(), Event{Message: "x"})
+	if err == nil {
+		t.Fatal("expected an error once retries are exhausted")
+	}
+	if inner.calls != 3 {
+		t.Errorf("expected maxRetries+1 = 3 calls, got %d", inner.calls)
+	}
+}
+
+func TestRetryingNotifier_stopsRetryingWhenContextCancelled(t *testing.T) {
+	inner := &failThenSucceedNotifier{failures: 100}
+	r := &retryingNotifier{
+		Notifier:   inner,
+		name:       "test",
+		maxRetries: 5,
+		retryDelay: time.Hour,
+		log:        testLogger(),
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	done := make(chan error, 1)
+	go func() { done <- r.Notify(ctx, Event{Message: "x"}) }()
+
+	select {
+	case err := <-done:
+		if !errors.Is(err, context.Canceled) {
+			t.Fatalf("expected context.Canceled, got: %v", err)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("Notify did not return promptly after context cancellation; retryDelay was not short-circuited")
+	}
+	if inner.calls != 1 {
+		t.Errorf("expected only the initial attempt before the cancelled retry delay, got %d calls", inner.calls)
+	}
+}
@@ -0,0 +1,73 @@
+package bot
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// WebhookNotifierConfig configures delivery as a JSON POST to an arbitrary
+// HTTP endpoint (Slack-style incoming webhooks, custom receivers, ...).
+type WebhookNotifierConfig struct {
+	URL     string            `yaml:"url"`
+	Headers map[string]string `yaml:"headers"`
+	Timeout time.Duration     `yaml:"timeout"`
+}
+
+type webhookPayload struct {
+	Nickname  string    `json:"nickname"`
+	Online    bool      `json:"online"`
+	Message   string    `json:"message"`
+	Timestamp time.Time `json:"timestamp"`
+}
+
+type webhookNotifier struct {
+	conf   *WebhookNotifierConfig
+	client *http.Client
+}
+
+func newWebhookNotifier(conf *WebhookNotifierConfig) *webhookNotifier {
+	timeout := conf.Timeout
+	if timeout == 0 {
+		timeout = 10 * time.Second
+	}
+	return &webhookNotifier{
+		conf:   conf,
+		client: &http.Client{Timeout: timeout},
+	}
+}
+
+func (n *webhookNotifier) Notify(ctx context.Context, event Event) error {
+	body, err := json.Marshal(webhookPayload{
+		Nickname:  event.Nickname,
+		Online:    event.Online,
+		Message:   event.Message,
+		Timestamp: event.Timestamp,
+	})
+	if err != nil {
+		return fmt.Errorf("marshaling webhook payload: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, n.conf.URL, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("building webhook request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for k, v := range n.conf.Headers {
+		req.Header.Set(k, v)
+	}
+
+	resp, err := n.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("posting webhook: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook returned status %d", resp.StatusCode)
+	}
+	return nil
+}
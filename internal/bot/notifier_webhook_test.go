@@ -0,0 +1,54 @@
+package bot
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestWebhookNotifier_notifySendsExpectedPayload(t *testing.T) {
+	var received webhookPayload
+	var gotHeader string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotHeader = r.Header.Get("X-Auth-Token")
+		if err := json.NewDecoder(r.Body).Decode(&received); err != nil {
+			t.Errorf("decoding webhook payload: %v", err)
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer srv.Close()
+
+	n := newWebhookNotifier(&WebhookNotifierConfig{
+		URL:     srv.URL,
+		Headers: map[string]string{"X-Auth-Token": "secret"},
+	})
+
+	event := Event{Nickname: "alice", Online: true, Message: "alice is online", Timestamp: time.Date(2026, 7, 26, 12, 0, 0, 0, time.UTC)}
+	if err := n.Notify(context.Background(), event); err != nil {
+		t.Fatalf("Notify returned an error: %v", err)
+	}
+
+	if received.Nickname != "alice" || !received.Online || received.Message != "alice is online" {
+		t.Errorf("unexpected payload received: %+v", received)
+	}
+	if gotHeader != "secret" {
+		t.Errorf("X-Auth-Token header = %q, want %q", gotHeader, "secret")
+	}
+}
+
+func TestWebhookNotifier_notifyErrorsOnNonSuccessStatus(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer srv.Close()
+
+	n := newWebhookNotifier(&WebhookNotifierConfig{URL: srv.URL})
+
+	err := n.Notify(context.Background(), Event{Message: "x", Timestamp: time.Now()})
+	if err == nil {
+		t.Fatal("expected an error for a 500 response")
+	}
+}
@@ -0,0 +1,226 @@
+package bot
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"encoding/base64"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net"
+	"time"
+)
+
+// XMPPNotifierConfig configures delivery as a one-off XMPP chat message,
+// authenticated with SASL PLAIN. The connection is upgraded with STARTTLS
+// when the server advertises it; otherwise it stays plaintext.
+type XMPPNotifierConfig struct {
+	Server             string `yaml:"server"`
+	Port               string `yaml:"port"`
+	Username           string `yaml:"username"`
+	Password           string `yaml:"password"`
+	To                 string `yaml:"to"`
+	InsecureSkipVerify bool   `yaml:"insecure_skip_verify"`
+}
+
+type xmppNotifier struct {
+	conf *XMPPNotifierConfig
+}
+
+func newXMPPNotifier(conf *XMPPNotifierConfig) *xmppNotifier {
+	return &xmppNotifier{conf: conf}
+}
+
+const (
+	xmppNSStream = "http://etherx.jabber.org/streams"
+	xmppNSTLS    = "urn:ietf:params:xml:ns:xmpp-tls"
+	xmppNSSASL   = "urn:ietf:params:xml:ns:xmpp-sasl"
+	xmppNSBind   = "urn:ietf:params:xml:ns:xmpp-bind"
+	xmppNSClient = "jabber:client"
+)
+
+// streamFeatures is the <stream:features/> stanza the server sends after
+// every stream (re)start, advertising what's available at that stage:
+// STARTTLS before the TLS upgrade, SASL mechanisms after it, then resource
+// binding once authenticated.
+type streamFeatures struct {
+	XMLName  xml.Name `xml:"features"`
+	StartTLS *struct {
+		XMLName xml.Name `xml:"starttls"`
+	} `xml:"starttls"`
+	Mechanisms *struct {
+		Mechanism []string `xml:"mechanism"`
+	} `xml:"mechanisms"`
+	Bind *struct {
+		XMLName xml.Name `xml:"bind"`
+	} `xml:"bind"`
+}
+
+// Notify drives a real XMPP session end to end: connect in plaintext,
+// negotiate the STARTTLS upgrade almost every server requires on the
+// standard port, authenticate with SASL PLAIN, bind a resource (without
+// which practically every server rejects stanzas from the connection),
+// send a single chat message, then tear the stream down. Each event gets
+// its own short-lived session rather than a persistent connection, the
+// same way the SMTP notifier treats delivery as a standalone operation.
+func (n *xmppNotifier) Notify(ctx context.Context, event Event) error {
+	conn, err := (&net.Dialer{}).DialContext(ctx, "tcp", net.JoinHostPort(n.conf.Server, n.conf.Port))
+	if err != nil {
+		return fmt.Errorf("dialing xmpp server: %w", err)
+	}
+	defer conn.Close()
+
+	deadline := time.Now().Add(15 * time.Second)
+	if dl, ok := ctx.Deadline(); ok {
+		deadline = dl
+	}
+	conn.SetDeadline(deadline)
+
+	var rw io.ReadWriter = conn
+	if err := writeXMPPStreamHeader(rw, n.conf.Server); err != nil {
+		return fmt.Errorf("opening xmpp stream: %w", err)
+	}
+	dec := xml.NewDecoder(rw)
+	features, err := readXMPPFeatures(dec)
+	if err != nil {
+		return fmt.Errorf("reading stream features: %w", err)
+	}
+
+	if features.StartTLS != nil {
+		if _, err := fmt.Fprintf(rw, "<starttls xmlns='%s'/>", xmppNSTLS); err != nil {
+			return fmt.Errorf("requesting starttls: %w", err)
+		}
+		se, err := nextXMPPElement(dec)
+		if err != nil {
+			return fmt.Errorf("reading starttls response: %w", err)
+		}
+		if se.Name.Local != "proceed" {
+			return fmt.Errorf("server refused starttls upgrade")
+		}
+
+		tlsConn := tls.Client(conn, &tls.Config{
+			ServerName:         n.conf.Server,
+			InsecureSkipVerify: n.conf.InsecureSkipVerify,
+		})
+		if err := tlsConn.HandshakeContext(ctx); err != nil {
+			return fmt.Errorf("xmpp starttls handshake: %w", err)
+		}
+		rw = tlsConn
+
+		if err := writeXMPPStreamHeader(rw, n.conf.Server); err != nil {
+			return fmt.Errorf("restarting xmpp stream after starttls: %w", err)
+		}
+		dec = xml.NewDecoder(rw)
+		if features, err = readXMPPFeatures(dec); err != nil {
+			return fmt.Errorf("reading post-tls stream features: %w", err)
+		}
+	}
+
+	if features.Mechanisms == nil {
+		return fmt.Errorf("xmpp server did not offer any SASL mechanisms")
+	}
+	auth := fmt.Sprintf("\x00%s\x00%s", n.conf.Username, n.conf.Password)
+	if _, err := fmt.Fprintf(rw, "<auth xmlns='%s' mechanism='PLAIN'>%s</auth>", xmppNSSASL, base64.StdEncoding.EncodeToString([]byte(auth))); err != nil {
+		return fmt.Errorf("sending sasl auth: %w", err)
+	}
+	se, err := nextXMPPElement(dec)
+	if err != nil {
+		return fmt.Errorf("reading sasl response: %w", err)
+	}
+	if se.Name.Local != "success" {
+		return fmt.Errorf("xmpp SASL PLAIN authentication failed")
+	}
+
+	if err := writeXMPPStreamHeader(rw, n.conf.Server); err != nil {
+		return fmt.Errorf("restarting xmpp stream after auth: %w", err)
+	}
+	dec = xml.NewDecoder(rw)
+	if _, err := readXMPPFeatures(dec); err != nil {
+		return fmt.Errorf("reading post-auth stream features: %w", err)
+	}
+
+	if _, err := fmt.Fprintf(rw, "<iq type='set' id='notifybot-bind'><bind xmlns='%s'><resource>notifybot</resource></bind></iq>", xmppNSBind); err != nil {
+		return fmt.Errorf("requesting resource bind: %w", err)
+	}
+	se, err = nextXMPPElement(dec)
+	if err != nil {
+		return fmt.Errorf("reading bind response: %w", err)
+	}
+	var iq struct {
+		Type string `xml:"type,attr"`
+	}
+	if err := dec.DecodeElement(&iq, &se); err != nil {
+		return fmt.Errorf("decoding bind response: %w", err)
+	}
+	if se.Name.Local != "iq" || iq.Type != "result" {
+		return fmt.Errorf("xmpp resource bind failed")
+	}
+
+	body := fmt.Sprintf("[%s] %s", event.Timestamp.Format("2006-01-02 15:04:05 UTC"), event.Message)
+	if _, err := fmt.Fprintf(rw, "<message to='%s' type='chat'><body>%s</body></message>", n.conf.To, escapeXML(body)); err != nil {
+		return fmt.Errorf("sending xmpp message: %w", err)
+	}
+	fmt.Fprint(rw, "</stream:stream>")
+	return nil
+}
+
+func writeXMPPStreamHeader(w io.Writer, to string) error {
+	_, err := fmt.Fprintf(w, "<?xml version='1.0'?><stream:stream to='%s' xmlns='%s' xmlns:stream='%s' version='1.0'>",
+		to, xmppNSClient, xmppNSStream)
+	return err
+}
+
+// nextXMPPElement reads tokens until it finds a start element that isn't
+// the stream wrapper itself (whose closing tag isn't sent until the whole
+// session ends), and returns it unconsumed so the caller can decode it.
+func nextXMPPElement(dec *xml.Decoder) (xml.StartElement, error) {
+	for {
+		tok, err := dec.Token()
+		if err != nil {
+			return xml.StartElement{}, err
+		}
+		se, ok := tok.(xml.StartElement)
+		if !ok {
+			continue
+		}
+		if se.Name.Space == xmppNSStream && se.Name.Local == "stream" {
+			continue
+		}
+		return se, nil
+	}
+}
+
+// readXMPPFeatures reads and decodes the <stream:features/> stanza the
+// server is expected to send immediately after every stream (re)start.
+func readXMPPFeatures(dec *xml.Decoder) (*streamFeatures, error) {
+	se, err := nextXMPPElement(dec)
+	if err != nil {
+		return nil, err
+	}
+	if se.Name.Local != "features" {
+		return nil, fmt.Errorf("expected stream features, got <%s>", se.Name.Local)
+	}
+	var f streamFeatures
+	if err := dec.DecodeElement(&f, &se); err != nil {
+		return nil, fmt.Errorf("decoding stream features: %w", err)
+	}
+	return &f, nil
+}
+
+func escapeXML(s string) string {
+	var buf bytes.Buffer
+	for _, r := range s {
+		switch r {
+		case '&':
+			buf.WriteString("&amp;")
+		case '<':
+			buf.WriteString("&lt;")
+		case '>':
+			buf.WriteString("&gt;")
+		default:
+			buf.WriteRune(r)
+		}
+	}
+	return buf.String()
+}
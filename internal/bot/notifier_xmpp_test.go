@@ -0,0 +1,208 @@
+package bot
+
+import (
+	"bytes"
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"io"
+	"math/big"
+	"net"
+	"strings"
+	"testing"
+	"time"
+)
+
+// generateSelfSignedCert builds an in-memory cert/key pair valid for host,
+// so the stub XMPP server below can offer STARTTLS without a real CA.
+func generateSelfSignedCert(t *testing.T, host string) tls.Certificate {
+	t.Helper()
+
+	priv, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("generating test key: %v", err)
+	}
+	template := x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: host},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageKeyEncipherment | x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}
+	if ip := net.ParseIP(host); ip != nil {
+		template.IPAddresses = []net.IP{ip}
+	} else {
+		template.DNSNames = []string{host}
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, &template, &template, &priv.PublicKey, priv)
+	if err != nil {
+		t.Fatalf("creating test certificate: %v", err)
+	}
+	return tls.Certificate{Certificate: [][]byte{der}, PrivateKey: priv}
+}
+
+// readUntilMarker reads from r one byte at a time until the accumulated
+// buffer contains marker, returning everything read. It's just enough of a
+// "parser" to drive the hand-rolled stub server below without a real XML
+// reader on that side.
+func readUntilMarker(t *testing.T, r io.Reader, marker string) string {
+	t.Helper()
+	var buf bytes.Buffer
+	tmp := make([]byte, 1)
+	for !strings.Contains(buf.String(), marker) {
+		n, err := r.Read(tmp)
+		if err != nil {
+			t.Fatalf("reading stub xmpp stream (want %q): %v", marker, err)
+		}
+		buf.Write(tmp[:n])
+	}
+	return buf.String()
+}
+
+// runStubXMPPServer speaks just enough real XMPP to drive xmppNotifier.Notify
+// through stream restart, STARTTLS, SASL PLAIN, and resource bind, then
+// reports the <message> body it received on bodies.
+func runStubXMPPServer(t *testing.T, ln net.Listener, cert tls.Certificate, bodies chan<- string) {
+	conn, err := ln.Accept()
+	if err != nil {
+		t.Errorf("stub xmpp server accept: %v", err)
+		return
+	}
+	defer conn.Close()
+
+	readUntilMarker(t, conn, "version='1.0'>")
+	io.WriteString(conn, "<?xml version='1.0'?><stream:stream from='test' id='1' xmlns='jabber:client' xmlns:stream='http://etherx.jabber.org/streams' version='1.0'>"+
+		"<stream:features><starttls xmlns='urn:ietf:params:xml:ns:xmpp-tls'/></stream:features>")
+
+	readUntilMarker(t, conn, "xmpp-tls'/>")
+	io.WriteString(conn, "<proceed xmlns='urn:ietf:params:xml:ns:xmpp-tls'/>")
+
+	tlsConn := tls.Server(conn, &tls.Config{Certificates: []tls.Certificate{cert}})
+	if err := tlsConn.Handshake(); err != nil {
+		t.Errorf("stub xmpp server tls handshake: %v", err)
+		return
+	}
+	var rw io.ReadWriter = tlsConn
+
+	readUntilMarker(t, rw, "version='1.0'>")
+	io.WriteString(rw, "<?xml version='1.0'?><stream:stream from='test' id='2' xmlns='jabber:client' xmlns:stream='http://etherx.jabber.org/streams' version='1.0'>"+
+		"<stream:features><mechanisms xmlns='urn:ietf:params:xml:ns:xmpp-sasl'><mechanism>PLAIN</mechanism></mechanisms></stream:features>")
+
+	readUntilMarker(t, rw, "</auth>")
+	io.WriteString(rw, "<success xmlns='urn:ietf:params:xml:ns:xmpp-sasl'/>")
+
+	readUntilMarker(t, rw, "version='1.0'>")
+	io.WriteString(rw, "<?xml version='1.0'?><stream:stream from='test' id='3' xmlns='jabber:client' xmlns:stream='http://etherx.jabber.org/streams' version='1.0'>"+
+		"<stream:features><bind xmlns='urn:ietf:params:xml:ns:xmpp-bind'/></stream:features>")
+
+	readUntilMarker(t, rw, "</iq>")
+	io.WriteString(rw, "<iq type='result' id='notifybot-bind'><bind xmlns='urn:ietf:params:xml:ns:xmpp-bind'><jid>alice@test/notifybot</jid></bind></iq>")
+
+	msg := readUntilMarker(t, rw, "</message>")
+	bodies <- msg
+}
+
+func TestXMPPNotifier_fullHandshakeDeliversMessage(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listening for stub xmpp server: %v", err)
+	}
+	defer ln.Close()
+
+	host, port, _ := net.SplitHostPort(ln.Addr().String())
+	cert := generateSelfSignedCert(t, host)
+
+	bodies := make(chan string, 1)
+	go runStubXMPPServer(t, ln, cert, bodies)
+
+	n := newXMPPNotifier(&XMPPNotifierConfig{
+		Server:             host,
+		Port:               port,
+		Username:           "alice",
+		Password:           "hunter2",
+		To:                 "bob@test.example",
+		InsecureSkipVerify: true,
+	})
+
+	event := Event{Nickname: "alice", Online: true, Message: "alice is online", Timestamp: time.Date(2026, 7, 26, 12, 0, 0, 0, time.UTC)}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if err := n.Notify(ctx, event); err != nil {
+		t.Fatalf("Notify returned an error: %v", err)
+	}
+
+	select {
+	case msg := <-bodies:
+		if !strings.Contains(msg, "alice is online") {
+			t.Errorf("expected the message stanza to contain the event message, got %q", msg)
+		}
+		if !strings.Contains(msg, "to='bob@test.example'") {
+			t.Errorf("expected the message stanza to be addressed to conf.To, got %q", msg)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("stub server never received a <message> stanza")
+	}
+}
+
+func TestXMPPNotifier_authFailure(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listening for stub xmpp server: %v", err)
+	}
+	defer ln.Close()
+
+	host, port, _ := net.SplitHostPort(ln.Addr().String())
+	cert := generateSelfSignedCert(t, host)
+
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+
+		readUntilMarker(t, conn, "version='1.0'>")
+		io.WriteString(conn, "<?xml version='1.0'?><stream:stream from='test' id='1' xmlns='jabber:client' xmlns:stream='http://etherx.jabber.org/streams' version='1.0'>"+
+			"<stream:features><starttls xmlns='urn:ietf:params:xml:ns:xmpp-tls'/></stream:features>")
+
+		readUntilMarker(t, conn, "xmpp-tls'/>")
+		io.WriteString(conn, "<proceed xmlns='urn:ietf:params:xml:ns:xmpp-tls'/>")
+
+		tlsConn := tls.Server(conn, &tls.Config{Certificates: []tls.Certificate{cert}})
+		if err := tlsConn.Handshake(); err != nil {
+			return
+		}
+
+		readUntilMarker(t, tlsConn, "version='1.0'>")
+		io.WriteString(tlsConn, "<?xml version='1.0'?><stream:stream from='test' id='2' xmlns='jabber:client' xmlns:stream='http://etherx.jabber.org/streams' version='1.0'>"+
+			"<stream:features><mechanisms xmlns='urn:ietf:params:xml:ns:xmpp-sasl'><mechanism>PLAIN</mechanism></mechanisms></stream:features>")
+
+		readUntilMarker(t, tlsConn, "</auth>")
+		io.WriteString(tlsConn, "<failure xmlns='urn:ietf:params:xml:ns:xmpp-sasl'><not-authorized/></failure>")
+	}()
+
+	n := newXMPPNotifier(&XMPPNotifierConfig{
+		Server:             host,
+		Port:               port,
+		Username:           "alice",
+		Password:           "wrong",
+		To:                 "bob@test.example",
+		InsecureSkipVerify: true,
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	err = n.Notify(ctx, Event{Nickname: "alice", Online: true, Message: "x", Timestamp: time.Now()})
+	if err == nil || !strings.Contains(err.Error(), "authentication failed") {
+		t.Fatalf("expected an authentication-failed error, got: %v", err)
+	}
+}
@@ -0,0 +1,111 @@
+package bot
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+	"log/slog"
+	"net"
+	"strings"
+	"testing"
+	"time"
+)
+
+// scriptServer feeds script, one line per write, down conn, draining
+// whatever the other end (negotiateSASL) writes back so its Fprintf calls
+// never block on an unread pipe.
+func scriptServer(conn net.Conn, script []string) {
+	go io.Copy(io.Discard, conn)
+	go func() {
+		for _, line := range script {
+			conn.Write([]byte(line + "\r\n"))
+		}
+	}()
+}
+
+func newSASLTestBot(timeout time.Duration) *NotifyBot {
+	return &NotifyBot{
+		conf:        &Config{SASLUser: "alice", SASLPass: "hunter2"},
+		log:         slog.New(slog.NewTextHandler(&bytes.Buffer{}, nil)),
+		saslTimeout: timeout,
+	}
+}
+
+func TestNegotiateSASL_success(t *testing.T) {
+	client, server := net.Pipe()
+	defer client.Close()
+	defer server.Close()
+
+	scriptServer(server, []string{
+		":irc.example.net CAP * LS :sasl",
+		":irc.example.net CAP * ACK :sasl",
+		"AUTHENTICATE +",
+		":irc.example.net 903 notifybot :SASL authentication successful",
+	})
+
+	b := newSASLTestBot(2 * time.Second)
+	err := b.negotiateSASL(client, bufio.NewScanner(client))
+	if err != nil {
+		t.Fatalf("expected successful negotiation, got error: %v", err)
+	}
+}
+
+func TestNegotiateSASL_capNak(t *testing.T) {
+	client, server := net.Pipe()
+	defer client.Close()
+	defer server.Close()
+
+	scriptServer(server, []string{
+		":irc.example.net CAP * LS :sasl",
+		":irc.example.net CAP * NAK :sasl",
+	})
+
+	b := newSASLTestBot(2 * time.Second)
+	err := b.negotiateSASL(client, bufio.NewScanner(client))
+	if err == nil || !strings.Contains(err.Error(), "rejected") {
+		t.Fatalf("expected a capability-rejected error, got: %v", err)
+	}
+}
+
+func TestNegotiateSASL_authFailure(t *testing.T) {
+	client, server := net.Pipe()
+	defer client.Close()
+	defer server.Close()
+
+	scriptServer(server, []string{
+		":irc.example.net CAP * LS :sasl",
+		":irc.example.net CAP * ACK :sasl",
+		"AUTHENTICATE +",
+		":irc.example.net 904 notifybot :SASL authentication failed",
+	})
+
+	b := newSASLTestBot(2 * time.Second)
+	err := b.negotiateSASL(client, bufio.NewScanner(client))
+	if err == nil || !strings.Contains(err.Error(), "sasl authentication failed") {
+		t.Fatalf("expected an authentication-failed error, got: %v", err)
+	}
+}
+
+func TestNegotiateSASL_timesOutWhenServerNeverResponds(t *testing.T) {
+	client, server := net.Pipe()
+	defer client.Close()
+	defer server.Close()
+
+	go io.Copy(io.Discard, server) // drain CAP REQ/AUTHENTICATE but never reply
+
+	b := newSASLTestBot(50 * time.Millisecond)
+
+	done := make(chan error, 1)
+	go func() {
+		done <- b.negotiateSASL(client, bufio.NewScanner(client))
+	}()
+
+	select {
+	case err := <-done:
+		if err == nil {
+			t.Fatal("expected negotiateSASL to return an error once the deadline passed")
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("negotiateSASL did not return within its bound; it's hanging despite saslTimeout")
+	}
+}
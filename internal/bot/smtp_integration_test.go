@@ -0,0 +1,105 @@
+package bot
+
+import (
+	"bytes"
+	"log/slog"
+	"testing"
+	"time"
+
+	"notifybot/test/harness"
+)
+
+// newSMTPIntegrationBot builds a NotifyBot whose only notifier is a real
+// SMTP notifier pointed at mp, so handleISONResponse exercises the full
+// Notify -> smtp.SendMail -> Mailpit path instead of a method-level mock.
+func newSMTPIntegrationBot(mp *harness.Mailpit, nicknames map[string]bool, coldStartGrace time.Duration) *NotifyBot {
+	conf := &Config{
+		ColdStartGrace: coldStartGrace,
+		Notifiers: []NotifierConfig{
+			{
+				Type: "smtp",
+				SMTP: &SMTPNotifierConfig{
+					Host:        mp.SMTPHost,
+					Port:        mp.SMTPPort,
+					FromEmail:   "notifybot@example.com",
+					NotifyEmail: "watchers@example.com",
+				},
+			},
+		},
+	}
+	log := slog.New(slog.NewTextHandler(&bytes.Buffer{}, nil))
+	return NewNotifyBot(conf, log, nicknames)
+}
+
+func TestSMTPIntegration_onlineOfflineTransition(t *testing.T) {
+	mp := harness.StartMailpit(t)
+	bot := newSMTPIntegrationBot(mp, map[string]bool{"alice": false}, time.Nanosecond)
+
+	bot.handleISONResponse("alice")
+	mp.ExpectMail(t, "watchers@example.com", "alice is online")
+
+	bot.handleISONResponse("")
+	mp.ExpectMail(t, "watchers@example.com", "alice is offline")
+}
+
+func TestSMTPIntegration_coldStartGraceSuppressesMail(t *testing.T) {
+	mp := harness.StartMailpit(t)
+	bot := newSMTPIntegrationBot(mp, map[string]bool{"alice": false}, time.Hour)
+
+	bot.handleISONResponse("alice")
+
+	// No retryingNotifier delay is long enough to matter here: Notify
+	// either fires synchronously or it doesn't, so a short poll is enough
+	// to assert nothing was sent during the grace period.
+	time.Sleep(200 * time.Millisecond)
+	if n := mp.MessageCount(t); n != 0 {
+		t.Errorf("expected no mail during cold start grace period, got %d messages", n)
+	}
+}
+
+// newSMTPSubscriptionBot builds a NotifyBot routed through a subscription
+// with MinInterval set, rather than directly through Notifiers, so
+// handleISONResponse exercises the full subscriptionFilter -> Notify ->
+// smtp.SendMail -> Mailpit path.
+func newSMTPSubscriptionBot(mp *harness.Mailpit, nicknames map[string]bool, minInterval time.Duration) *NotifyBot {
+	conf := &Config{
+		ColdStartGrace: time.Nanosecond,
+		Notifiers: []NotifierConfig{
+			{
+				Type: "smtp",
+				Name: "ops",
+				SMTP: &SMTPNotifierConfig{
+					Host:        mp.SMTPHost,
+					Port:        mp.SMTPPort,
+					FromEmail:   "notifybot@example.com",
+					NotifyEmail: "watchers@example.com",
+				},
+			},
+		},
+		Subscriptions: []Subscription{
+			{Recipient: "ops", MinInterval: minInterval},
+		},
+	}
+	log := slog.New(slog.NewTextHandler(&bytes.Buffer{}, nil))
+	return NewNotifyBot(conf, log, nicknames)
+}
+
+func TestSMTPIntegration_subscriptionRateLimitsBurstToOneMail(t *testing.T) {
+	mp := harness.StartMailpit(t)
+	bot := newSMTPSubscriptionBot(mp, map[string]bool{"alice": false}, time.Minute)
+	defer bot.subFilter.Stop()
+
+	bot.handleISONResponse("alice")
+	mp.ExpectMail(t, "watchers@example.com", "alice is online")
+
+	// Both of these land within the same MinInterval window, so only the
+	// first should be delivered; the rest are suppressed and queued for a
+	// later digest rather than sent immediately.
+	bot.handleISONResponse("")
+	bot.handleISONResponse("alice")
+
+	time.Sleep(200 * time.Millisecond)
+	if n := mp.MessageCount(t); n != 1 {
+		t.Fatalf("expected the burst to collapse into a single mail, got %d messages", n)
+	}
+}
@@ -0,0 +1,46 @@
+package bot
+
+import (
+	"fmt"
+	"time"
+)
+
+// NickState is the persisted online/offline state for a single watched
+// nickname.
+type NickState struct {
+	Online     bool      `json:"online"`
+	LastChange time.Time `json:"last_change"`
+}
+
+// StateStore persists nickname presence so a restart doesn't have to treat
+// every watched nick as freshly offline and re-fire notifications for
+// whichever ones happen to be online when the first ISON reply arrives.
+type StateStore interface {
+	// Load returns the last known state for every nickname the store knows
+	// about. A nickname with no prior record is simply absent from the map.
+	Load() (map[string]NickState, error)
+
+	// Save persists a single nickname's state. Implementations must make
+	// this durable before returning, since it is called once per presence
+	// transition.
+	Save(nick string, online bool, lastChange time.Time) error
+}
+
+// StateStoreConfig selects and configures a StateStore backend.
+type StateStoreConfig struct {
+	Type string `yaml:"type"` // "json" or "sqlite"
+	Path string `yaml:"path"`
+}
+
+func buildStateStore(cfg StateStoreConfig) (StateStore, error) {
+	switch cfg.Type {
+	case "":
+		return nil, nil
+	case "json":
+		return newJSONStateStore(cfg.Path), nil
+	case "sqlite":
+		return newSQLiteStateStore(cfg.Path)
+	default:
+		return nil, fmt.Errorf("unknown state store type %q", cfg.Type)
+	}
+}
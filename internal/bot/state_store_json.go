@@ -0,0 +1,93 @@
+package bot
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// jsonStateStore persists nickname state as a single JSON file, rewritten
+// atomically (write to a temp file, then rename) on every Save so a crash
+// mid-write can never leave a truncated file behind.
+type jsonStateStore struct {
+	path string
+	mu   sync.Mutex
+}
+
+func newJSONStateStore(path string) *jsonStateStore {
+	return &jsonStateStore{path: path}
+}
+
+func (s *jsonStateStore) Load() (map[string]NickState, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	data, err := os.ReadFile(s.path)
+	if os.IsNotExist(err) {
+		return map[string]NickState{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	states := map[string]NickState{}
+	if len(data) == 0 {
+		return states, nil
+	}
+	if err := json.Unmarshal(data, &states); err != nil {
+		return nil, err
+	}
+	return states, nil
+}
+
+func (s *jsonStateStore) Save(nick string, online bool, lastChange time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	states, err := s.loadLocked()
+	if err != nil {
+		return err
+	}
+	states[nick] = NickState{Online: online, LastChange: lastChange}
+
+	data, err := json.MarshalIndent(states, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(s.path), filepath.Base(s.path)+".tmp-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), s.path)
+}
+
+// loadLocked is Load without re-acquiring s.mu, for use by Save.
+func (s *jsonStateStore) loadLocked() (map[string]NickState, error) {
+	data, err := os.ReadFile(s.path)
+	if os.IsNotExist(err) {
+		return map[string]NickState{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	states := map[string]NickState{}
+	if len(data) == 0 {
+		return states, nil
+	}
+	if err := json.Unmarshal(data, &states); err != nil {
+		return nil, err
+	}
+	return states, nil
+}
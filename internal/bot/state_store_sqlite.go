@@ -0,0 +1,74 @@
+package bot
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	_ "modernc.org/sqlite"
+)
+
+// sqliteStateStore persists nickname state in a SQLite database, the same
+// approach ubuntu-push uses for its "seen state" table.
+type sqliteStateStore struct {
+	db *sql.DB
+}
+
+func newSQLiteStateStore(path string) (*sqliteStateStore, error) {
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		return nil, fmt.Errorf("opening sqlite state store: %w", err)
+	}
+
+	const schema = `
+CREATE TABLE IF NOT EXISTS nick_state (
+	nick        TEXT PRIMARY KEY,
+	online      INTEGER NOT NULL,
+	last_change TEXT NOT NULL
+);`
+	if _, err := db.Exec(schema); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("creating nick_state table: %w", err)
+	}
+
+	return &sqliteStateStore{db: db}, nil
+}
+
+func (s *sqliteStateStore) Load() (map[string]NickState, error) {
+	rows, err := s.db.Query(`SELECT nick, online, last_change FROM nick_state`)
+	if err != nil {
+		return nil, fmt.Errorf("loading nick_state: %w", err)
+	}
+	defer rows.Close()
+
+	states := map[string]NickState{}
+	for rows.Next() {
+		var nick string
+		var online int
+		var lastChange string
+		if err := rows.Scan(&nick, &online, &lastChange); err != nil {
+			return nil, fmt.Errorf("scanning nick_state row: %w", err)
+		}
+		t, err := time.Parse(time.RFC3339, lastChange)
+		if err != nil {
+			return nil, fmt.Errorf("parsing last_change for %q: %w", nick, err)
+		}
+		states[nick] = NickState{Online: online != 0, LastChange: t}
+	}
+	return states, rows.Err()
+}
+
+func (s *sqliteStateStore) Save(nick string, online bool, lastChange time.Time) error {
+	const upsert = `
+INSERT INTO nick_state (nick, online, last_change) VALUES (?, ?, ?)
+ON CONFLICT(nick) DO UPDATE SET online = excluded.online, last_change = excluded.last_change;`
+
+	onlineInt := 0
+	if online {
+		onlineInt = 1
+	}
+	if _, err := s.db.Exec(upsert, nick, onlineInt, lastChange.Format(time.RFC3339)); err != nil {
+		return fmt.Errorf("saving state for %q: %w", nick, err)
+	}
+	return nil
+}
@@ -0,0 +1,42 @@
+package bot
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestJSONStateStore_SaveAndLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state.json")
+	store := newJSONStateStore(path)
+
+	now := time.Now().UTC().Truncate(time.Second)
+	if err := store.Save("alice", true, now); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+	if err := store.Save("bob", false, now); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+
+	states, err := store.Load()
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if !states["alice"].Online {
+		t.Error("expected alice to be persisted as online")
+	}
+	if states["bob"].Online {
+		t.Error("expected bob to be persisted as offline")
+	}
+}
+
+func TestJSONStateStore_LoadMissingFile(t *testing.T) {
+	store := newJSONStateStore(filepath.Join(t.TempDir(), "missing.json"))
+	states, err := store.Load()
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if len(states) != 0 {
+		t.Errorf("expected empty state for a missing file, got %v", states)
+	}
+}
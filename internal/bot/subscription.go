@@ -0,0 +1,342 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Subscription routes presence events for a subset of watched nicknames to
+// a single recipient (matching the Name, or failing that the Type, of a
+// NotifierConfig stanza), with its own rate limit and quiet hours. This is
+// what keeps a flaky client that reconnects in a loop from turning into an
+// SES bill: each (recipient, nick) pair gets at most one notification per
+// MinInterval, and nothing at all during QuietHours, with what's
+// suppressed batched into a single digest once the quiet window ends.
+type Subscription struct {
+	// Recipient identifies which configured notifier this subscription
+	// routes to.
+	Recipient string `yaml:"recipient"`
+
+	// Nicks restricts this subscription to a subset of watched nicknames.
+	// Empty means all of them.
+	Nicks []string `yaml:"nicks"`
+
+	// Events restricts which transitions are delivered: "online",
+	// "offline", or "both". Empty means both.
+	Events []string `yaml:"events"`
+
+	// MinInterval caps delivery to at most one notification per this
+	// interval, per (Recipient, nickname) pair. Zero disables rate
+	// limiting.
+	MinInterval time.Duration `yaml:"min_interval"`
+
+	// QuietHours is a "HH:MM-HH:MM [TZ]" window (e.g. "22:00-07:00 UTC",
+	// defaulting to UTC if the zone is omitted) during which nothing is
+	// delivered immediately; suppressed events are queued and sent as a
+	// single digest once the window ends. Empty disables quiet hours.
+	QuietHours string `yaml:"quiet_hours"`
+}
+
+// matches reports whether this subscription cares about a transition of
+// nickname to the given online state.
+func (s *Subscription) matches(nickname string, online bool) bool {
+	if len(s.Nicks) > 0 && !slicesContains(s.Nicks, nickname) {
+		return false
+	}
+	if len(s.Events) == 0 {
+		return true
+	}
+	for _, e := range s.Events {
+		switch strings.ToLower(e) {
+		case "both":
+			return true
+		case "online":
+			if online {
+				return true
+			}
+		case "offline":
+			if !online {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// tokenBucket is a capacity-1 token bucket: it allows at most one event,
+// then refills at 1/MinInterval per second, enough to cap a (recipient,
+// nick) pair to at most one notification per MinInterval.
+type tokenBucket struct {
+	capacity     float64
+	tokens       float64
+	refillPerSec float64
+	last         time.Time
+}
+
+func newTokenBucket(minInterval time.Duration) *tokenBucket {
+	if minInterval <= 0 {
+		return nil
+	}
+	return &tokenBucket{
+		capacity:     1,
+		tokens:       1,
+		refillPerSec: 1 / minInterval.Seconds(),
+	}
+}
+
+// Allow reports whether an event at time now may proceed, consuming a
+// token if so. Passing now explicitly (rather than reading time.Now()
+// internally) keeps this deterministic to test.
+func (b *tokenBucket) Allow(now time.Time) bool {
+	if !b.last.IsZero() {
+		if elapsed := now.Sub(b.last).Seconds(); elapsed > 0 {
+			b.tokens += elapsed * b.refillPerSec
+			if b.tokens > b.capacity {
+				b.tokens = b.capacity
+			}
+		}
+	}
+	b.last = now
+
+	if b.tokens >= 1 {
+		b.tokens--
+		return true
+	}
+	return false
+}
+
+// inQuietHours reports whether now falls inside the quiet window described
+// by spec, e.g. "22:00-07:00 UTC". An empty spec means there is no quiet
+// window. The window may wrap past midnight.
+func inQuietHours(now time.Time, spec string) bool {
+	if spec == "" {
+		return false
+	}
+
+	fields := strings.Fields(spec)
+	loc := time.UTC
+	if len(fields) > 1 {
+		if l, err := time.LoadLocation(fields[1]); err == nil {
+			loc = l
+		}
+	}
+
+	start, end, ok := strings.Cut(fields[0], "-")
+	if !ok {
+		return false
+	}
+	startT, err1 := time.Parse("15:04", start)
+	endT, err2 := time.Parse("15:04", end)
+	if err1 != nil || err2 != nil {
+		return false
+	}
+
+	now = now.In(loc)
+	nowMins := now.Hour()*60 + now.Minute()
+	startMins := startT.Hour()*60 + startT.Minute()
+	endMins := endT.Hour()*60 + endT.Minute()
+
+	if startMins <= endMins {
+		return nowMins >= startMins && nowMins < endMins
+	}
+	return nowMins >= startMins || nowMins < endMins // wraps past midnight
+}
+
+// nickState is the rate-limit and digest state for one nickname matched by
+// a subEntry. Each nickname gets its own bucket and queue so that a
+// Subscription covering several nicks can't have one nick's traffic
+// consume another's rate limit budget or land in another's digest.
+type nickState struct {
+	bucket       *tokenBucket
+	queued       []string
+	queuedSince  time.Time
+	inQuietHours bool
+}
+
+// defaultDigestFlushInterval is how long a queued event may sit, with
+// nothing else to trigger a flush, before checkFlush sends it as a digest
+// anyway, for a subscription with no MinInterval. A subscription that sets
+// MinInterval uses MinInterval as this interval instead, which is what
+// flushes its rate-limited events when no QuietHours are configured.
+const defaultDigestFlushInterval = 5 * time.Minute
+
+// flushCheckInterval is how often subscriptionFilter's background loop
+// re-checks every entry for a due flush, so a digest is delivered even if
+// no further matching event ever arrives to piggyback on.
+const flushCheckInterval = 30 * time.Second
+
+// subEntry is one Subscription bound to the notifier it routes to, plus
+// the per-nickname rate-limit and digest state that make delivery
+// decisions stateful.
+type subEntry struct {
+	sub      Subscription
+	notifier Notifier
+
+	mu    sync.Mutex
+	nicks map[string]*nickState
+}
+
+// nickStateLocked returns the nickState for nick, creating it (with its
+// own token bucket) on first use. Callers must hold e.mu.
+func (e *subEntry) nickStateLocked(nick string) *nickState {
+	if e.nicks == nil {
+		e.nicks = make(map[string]*nickState)
+	}
+	st, ok := e.nicks[nick]
+	if !ok {
+		st = &nickState{bucket: newTokenBucket(e.sub.MinInterval)}
+		e.nicks[nick] = st
+	}
+	return st
+}
+
+func (e *subEntry) deliver(log *slog.Logger, event Event) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	now := event.Timestamp
+	st := e.nickStateLocked(event.Nickname)
+	quiet := inQuietHours(now, e.sub.QuietHours)
+
+	if st.inQuietHours && !quiet && len(st.queued) > 0 {
+		e.flushDigestLocked(log, event.Nickname, st)
+	}
+	st.inQuietHours = quiet
+
+	if quiet {
+		e.enqueueLocked(st, now, event.Message)
+		return
+	}
+
+	if st.bucket != nil && !st.bucket.Allow(now) {
+		log.Info("Suppressing notification due to rate limit", "recipient", e.sub.Recipient, "nickname", event.Nickname)
+		e.enqueueLocked(st, now, event.Message)
+		return
+	}
+
+	if err := e.notifier.Notify(context.Background(), event); err != nil {
+		log.Error("Error delivering subscription notification", "recipient", e.sub.Recipient, "error", err)
+	}
+}
+
+// enqueueLocked appends message to st's digest queue, recording when the
+// queue first became non-empty so a stale queue can be flushed later even
+// if nothing else ever triggers it. Callers must hold e.mu.
+func (e *subEntry) enqueueLocked(st *nickState, now time.Time, message string) {
+	if len(st.queued) == 0 {
+		st.queuedSince = now
+	}
+	st.queued = append(st.queued, message)
+}
+
+// flushDigestLocked sends everything queued for nick as a single
+// notification. Callers must hold e.mu.
+func (e *subEntry) flushDigestLocked(log *slog.Logger, nick string, st *nickState) {
+	digest := Event{
+		Nickname:  nick,
+		Message:   fmt.Sprintf("%d suppressed notification(s) for %s:\n%s", len(st.queued), nick, strings.Join(st.queued, "\n")),
+		Timestamp: time.Now().UTC(),
+	}
+	if err := e.notifier.Notify(context.Background(), digest); err != nil {
+		log.Error("Error delivering digest notification", "recipient", e.sub.Recipient, "nickname", nick, "error", err)
+	}
+	st.queued = nil
+	st.queuedSince = time.Time{}
+}
+
+// checkFlush looks for any nickname whose digest queue is due to be sent
+// even though no further live event has arrived to trigger it: either its
+// quiet-hours window has ended, or the queue has simply sat for longer
+// than the subscription's flush interval (the case that matters when
+// MinInterval is set without QuietHours, since inQuietHours never
+// transitions in that configuration).
+func (e *subEntry) checkFlush(log *slog.Logger, now time.Time) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	quiet := inQuietHours(now, e.sub.QuietHours)
+	flushAfter := e.sub.MinInterval
+	if flushAfter <= 0 {
+		flushAfter = defaultDigestFlushInterval
+	}
+
+	for nick, st := range e.nicks {
+		transitionedOutOfQuiet := st.inQuietHours && !quiet
+		st.inQuietHours = quiet
+		if len(st.queued) == 0 {
+			continue
+		}
+		stale := !st.queuedSince.IsZero() && now.Sub(st.queuedSince) >= flushAfter
+		if !quiet && (transitionedOutOfQuiet || stale) {
+			e.flushDigestLocked(log, nick, st)
+		}
+	}
+}
+
+// subscriptionFilter sits between handleISONResponse and the configured
+// notifiers, routing each event to whichever subscriptions match it and
+// letting their rate limit / quiet hours decide whether (and when) it's
+// actually delivered. A background goroutine periodically flushes any
+// digest that's due even without a new event to piggyback on; call Stop
+// when the filter is replaced (e.g. on reload) to avoid leaking it.
+type subscriptionFilter struct {
+	log     *slog.Logger
+	entries []*subEntry
+	stop    chan struct{}
+}
+
+// newSubscriptionFilter builds a filter from the configured subscriptions.
+// A subscription whose Recipient doesn't match any notifier is logged and
+// skipped rather than treated as a fatal config error.
+func newSubscriptionFilter(subs []Subscription, notifiers map[string]Notifier, log *slog.Logger) *subscriptionFilter {
+	f := &subscriptionFilter{log: log, stop: make(chan struct{})}
+	for _, sub := range subs {
+		notifier, ok := notifiers[sub.Recipient]
+		if !ok {
+			log.Error("Subscription references unknown recipient, skipping", "recipient", sub.Recipient)
+			continue
+		}
+		f.entries = append(f.entries, &subEntry{
+			sub:      sub,
+			notifier: notifier,
+		})
+	}
+	go f.flushLoop()
+	return f
+}
+
+// flushLoop periodically checks every entry for a due digest flush, so
+// suppressed notifications are never stuck waiting on an event that might
+// not come. It exits once Stop is called.
+func (f *subscriptionFilter) flushLoop() {
+	ticker := time.NewTicker(flushCheckInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-f.stop:
+			return
+		case now := <-ticker.C:
+			for _, e := range f.entries {
+				e.checkFlush(f.log, now)
+			}
+		}
+	}
+}
+
+// Stop ends the background flush loop. Safe to call once per filter.
+func (f *subscriptionFilter) Stop() {
+	close(f.stop)
+}
+
+func (f *subscriptionFilter) dispatch(event Event) {
+	for _, e := range f.entries {
+		if !e.sub.matches(event.Nickname, event.Online) {
+			continue
+		}
+		e.deliver(f.log, event)
+	}
+}
@@ -0,0 +1,196 @@
+package bot
+
+import (
+	"bytes"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSubscription_matches(t *testing.T) {
+	cases := []struct {
+		name   string
+		sub    Subscription
+		nick   string
+		online bool
+		want   bool
+	}{
+		{"no filters matches anything", Subscription{}, "alice", true, true},
+		{"nick allow-list matches", Subscription{Nicks: []string{"alice", "bob"}}, "alice", true, true},
+		{"nick allow-list excludes", Subscription{Nicks: []string{"bob"}}, "alice", true, false},
+		{"online-only matches online", Subscription{Events: []string{"online"}}, "alice", true, true},
+		{"online-only excludes offline", Subscription{Events: []string{"online"}}, "alice", false, false},
+		{"offline-only matches offline", Subscription{Events: []string{"offline"}}, "alice", false, true},
+		{"both matches either", Subscription{Events: []string{"both"}}, "alice", false, true},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			if got := c.sub.matches(c.nick, c.online); got != c.want {
+				t.Errorf("matches(%q, %v) = %v, want %v", c.nick, c.online, got, c.want)
+			}
+		})
+	}
+}
+
+func TestTokenBucket_rateLimits(t *testing.T) {
+	b := newTokenBucket(time.Minute)
+	now := time.Now()
+
+	if !b.Allow(now) {
+		t.Fatal("first call should be allowed")
+	}
+	if b.Allow(now.Add(time.Second)) {
+		t.Fatal("second call within MinInterval should be suppressed")
+	}
+	if !b.Allow(now.Add(time.Minute)) {
+		t.Fatal("call a full MinInterval later should be allowed")
+	}
+}
+
+func TestTokenBucket_disabledWhenZero(t *testing.T) {
+	if newTokenBucket(0) != nil {
+		t.Fatal("expected a zero MinInterval to disable rate limiting entirely")
+	}
+}
+
+func TestInQuietHours(t *testing.T) {
+	day := time.Date(2026, 7, 26, 0, 0, 0, 0, time.UTC)
+	cases := []struct {
+		name string
+		time time.Time
+		spec string
+		want bool
+	}{
+		{"empty spec never quiet", day.Add(23 * time.Hour), "", false},
+		{"inside wrap-around window", day.Add(23 * time.Hour), "22:00-07:00 UTC", true},
+		{"inside wrap-around window after midnight", day.Add(3 * time.Hour), "22:00-07:00 UTC", true},
+		{"outside wrap-around window", day.Add(12 * time.Hour), "22:00-07:00 UTC", false},
+		{"inside same-day window", day.Add(13 * time.Hour), "12:00-14:00 UTC", true},
+		{"outside same-day window", day.Add(15 * time.Hour), "12:00-14:00 UTC", false},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			if got := inQuietHours(c.time, c.spec); got != c.want {
+				t.Errorf("inQuietHours(%s, %q) = %v, want %v", c.time, c.spec, got, c.want)
+			}
+		})
+	}
+}
+
+func TestSubscriptionFilter_ratesLimitsPerRecipientAndNick(t *testing.T) {
+	mock := &mockNotifier{}
+	f := newSubscriptionFilter(
+		[]Subscription{{Recipient: "ops", MinInterval: time.Minute}},
+		map[string]Notifier{"ops": mock},
+		testLogger(),
+	)
+	defer f.Stop()
+
+	base := time.Date(2026, 7, 26, 12, 0, 0, 0, time.UTC)
+	f.dispatch(Event{Nickname: "alice", Online: true, Message: "alice is online", Timestamp: base})
+	f.dispatch(Event{Nickname: "alice", Online: false, Message: "alice is offline", Timestamp: base.Add(time.Second)})
+
+	if len(mock.events) != 1 {
+		t.Fatalf("expected only the first event to be delivered, got %d", len(mock.events))
+	}
+	if mock.events[0].Message != "alice is online" {
+		t.Errorf("unexpected delivered event: %+v", mock.events[0])
+	}
+}
+
+func TestSubscriptionFilter_quietHoursDigest(t *testing.T) {
+	mock := &mockNotifier{}
+	f := newSubscriptionFilter(
+		[]Subscription{{Recipient: "ops", QuietHours: "22:00-07:00 UTC"}},
+		map[string]Notifier{"ops": mock},
+		testLogger(),
+	)
+	defer f.Stop()
+
+	night := time.Date(2026, 7, 26, 23, 0, 0, 0, time.UTC)
+	morning := time.Date(2026, 7, 27, 8, 0, 0, 0, time.UTC)
+
+	f.dispatch(Event{Nickname: "alice", Online: true, Message: "alice is online", Timestamp: night})
+	if len(mock.events) != 0 {
+		t.Fatalf("expected no immediate delivery during quiet hours, got %d", len(mock.events))
+	}
+
+	f.dispatch(Event{Nickname: "alice", Online: false, Message: "alice is offline", Timestamp: morning})
+	if len(mock.events) != 2 {
+		t.Fatalf("expected a digest plus the new event, got %d", len(mock.events))
+	}
+	if !strings.Contains(mock.events[0].Message, "alice is online") {
+		t.Errorf("expected digest to contain the suppressed message, got %q", mock.events[0].Message)
+	}
+}
+
+func TestSubscriptionFilter_ratesLimitPerNickNotPerRecipient(t *testing.T) {
+	mock := &mockNotifier{}
+	f := newSubscriptionFilter(
+		[]Subscription{{Recipient: "ops", MinInterval: time.Minute}},
+		map[string]Notifier{"ops": mock},
+		testLogger(),
+	)
+	defer f.Stop()
+
+	base := time.Date(2026, 7, 26, 12, 0, 0, 0, time.UTC)
+	f.dispatch(Event{Nickname: "alice", Online: true, Message: "alice is online", Timestamp: base})
+	f.dispatch(Event{Nickname: "bob", Online: true, Message: "bob is online", Timestamp: base.Add(time.Second)})
+
+	if len(mock.events) != 2 {
+		t.Fatalf("expected bob's event to have its own rate limit budget, got %d delivered", len(mock.events))
+	}
+
+	// A second event for alice within the window is still suppressed.
+	f.dispatch(Event{Nickname: "alice", Online: false, Message: "alice is offline", Timestamp: base.Add(2 * time.Second)})
+	if len(mock.events) != 2 {
+		t.Fatalf("expected alice's second event within MinInterval to still be suppressed, got %d delivered", len(mock.events))
+	}
+}
+
+func TestSubscriptionFilter_flushesStaleQueueWithoutQuietHours(t *testing.T) {
+	mock := &mockNotifier{}
+	f := newSubscriptionFilter(
+		[]Subscription{{Recipient: "ops", MinInterval: time.Minute}},
+		map[string]Notifier{"ops": mock},
+		testLogger(),
+	)
+	defer f.Stop()
+
+	base := time.Date(2026, 7, 26, 12, 0, 0, 0, time.UTC)
+	f.dispatch(Event{Nickname: "alice", Online: true, Message: "alice is online", Timestamp: base})
+	f.dispatch(Event{Nickname: "alice", Online: false, Message: "alice is offline", Timestamp: base.Add(time.Second)})
+
+	if len(mock.events) != 1 {
+		t.Fatalf("expected the second event to be rate-limited and queued, got %d delivered", len(mock.events))
+	}
+
+	// No quiet hours are configured, so nothing about dispatch itself ever
+	// flips alice's nickState.inQuietHours; only the periodic backstop check
+	// can flush this.
+	f.entries[0].checkFlush(f.log, base.Add(defaultDigestFlushInterval))
+
+	if len(mock.events) != 2 {
+		t.Fatalf("expected the stale queue to be flushed as a digest, got %d delivered", len(mock.events))
+	}
+	if !strings.Contains(mock.events[1].Message, "alice is offline") {
+		t.Errorf("expected digest to contain the suppressed message, got %q", mock.events[1].Message)
+	}
+}
+
+func TestSubscriptionFilter_unknownRecipientSkipped(t *testing.T) {
+	f := newSubscriptionFilter(
+		[]Subscription{{Recipient: "missing"}},
+		map[string]Notifier{"ops": &mockNotifier{}},
+		testLogger(),
+	)
+	if len(f.entries) != 0 {
+		t.Errorf("expected a subscription with an unknown recipient to be skipped, got %d entries", len(f.entries))
+	}
+}
+
+// testLogger returns a logger that discards output.
+func testLogger() *slog.Logger {
+	return slog.New(slog.NewTextHandler(&bytes.Buffer{}, nil))
+}
@@ -0,0 +1,110 @@
+// Package irc implements a small RFC 1459 / IRCv3 message parser. It
+// replaces splitting raw lines on spaces and indexing into the result,
+// which breaks on trailing whitespace, messages containing colons, CTCP
+// payloads, and IRCv3 message tags.
+package irc
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Message is a single parsed IRC line:
+//
+//	[@tags] [:prefix] COMMAND [params...] [:trailing]
+type Message struct {
+	Tags     map[string]string
+	Prefix   string
+	Command  string
+	Params   []string
+	Trailing string
+
+	// HasTrailing distinguishes an absent trailing parameter from an empty
+	// one (e.g. "PRIVMSG #chan :" has a present-but-empty trailing param).
+	HasTrailing bool
+}
+
+// Nick returns the nickname portion of Prefix (the part before "!"), or
+// Prefix unchanged if it isn't a full nick!user@host mask. This is commonly
+// a server name for server-generated messages.
+func (m *Message) Nick() string {
+	if i := strings.IndexByte(m.Prefix, '!'); i != -1 {
+		return m.Prefix[:i]
+	}
+	return m.Prefix
+}
+
+// Parse parses a single raw IRC line, with or without a trailing CRLF.
+func Parse(line string) (*Message, error) {
+	line = strings.TrimRight(line, "\r\n")
+	if line == "" {
+		return nil, fmt.Errorf("irc: empty message")
+	}
+
+	msg := &Message{}
+
+	if strings.HasPrefix(line, "@") {
+		sp := strings.IndexByte(line, ' ')
+		if sp == -1 {
+			return nil, fmt.Errorf("irc: malformed tags in %q", line)
+		}
+		msg.Tags = parseTags(line[1:sp])
+		line = strings.TrimLeft(line[sp+1:], " ")
+	}
+
+	if strings.HasPrefix(line, ":") {
+		sp := strings.IndexByte(line, ' ')
+		if sp == -1 {
+			return nil, fmt.Errorf("irc: malformed prefix in %q", line)
+		}
+		msg.Prefix = line[1:sp]
+		line = strings.TrimLeft(line[sp+1:], " ")
+	}
+
+	if idx := strings.Index(line, " :"); idx != -1 {
+		msg.Trailing = line[idx+2:]
+		msg.HasTrailing = true
+		line = line[:idx]
+	} else if line == ":" || strings.HasPrefix(line, ":") {
+		msg.Trailing = strings.TrimPrefix(line, ":")
+		msg.HasTrailing = true
+		line = ""
+	}
+
+	fields := strings.Fields(line)
+	if len(fields) == 0 {
+		return nil, fmt.Errorf("irc: missing command in %q", line)
+	}
+	msg.Command = strings.ToUpper(fields[0])
+	if len(fields) > 1 {
+		msg.Params = fields[1:]
+	}
+
+	return msg, nil
+}
+
+// parseTags parses the IRCv3 tags portion of a line (the part between "@"
+// and the first space), e.g. "id=123;time=2023-01-01T00:00:00Z".
+func parseTags(s string) map[string]string {
+	tags := make(map[string]string)
+	for _, kv := range strings.Split(s, ";") {
+		if kv == "" {
+			continue
+		}
+		key, val, _ := strings.Cut(kv, "=")
+		tags[key] = unescapeTagValue(val)
+	}
+	return tags
+}
+
+var tagUnescaper = strings.NewReplacer(
+	`\:`, ";",
+	`\s`, " ",
+	`\r`, "\r",
+	`\n`, "\n",
+	`\\`, `\`,
+)
+
+func unescapeTagValue(s string) string {
+	return tagUnescaper.Replace(s)
+}
@@ -0,0 +1,108 @@
+package irc
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	cases := []struct {
+		name string
+		line string
+		want *Message
+	}{
+		{
+			name: "PING with trailing",
+			line: "PING :irc.example.com",
+			want: &Message{Command: "PING", Trailing: "irc.example.com", HasTrailing: true},
+		},
+		{
+			name: "numeric reply with prefix and params",
+			line: ":irc.example.com 303 notifybot :alice bob",
+			want: &Message{
+				Prefix:      "irc.example.com",
+				Command:     "303",
+				Params:      []string{"notifybot"},
+				Trailing:    "alice bob",
+				HasTrailing: true,
+			},
+		},
+		{
+			name: "PRIVMSG with colon in the message body",
+			line: ":alice!user@host PRIVMSG #chan :time is 10:30, see you then",
+			want: &Message{
+				Prefix:      "alice!user@host",
+				Command:     "PRIVMSG",
+				Params:      []string{"#chan"},
+				Trailing:    "time is 10:30, see you then",
+				HasTrailing: true,
+			},
+		},
+		{
+			name: "CTCP VERSION payload",
+			line: ":alice!user@host PRIVMSG notifybot :\x01VERSION\x01",
+			want: &Message{
+				Prefix:      "alice!user@host",
+				Command:     "PRIVMSG",
+				Params:      []string{"notifybot"},
+				Trailing:    "\x01VERSION\x01",
+				HasTrailing: true,
+			},
+		},
+		{
+			name: "message tags",
+			line: "@id=123;time=2023-01-01T00:00:00Z :alice!user@host PRIVMSG #chan :hi",
+			want: &Message{
+				Tags:        map[string]string{"id": "123", "time": "2023-01-01T00:00:00Z"},
+				Prefix:      "alice!user@host",
+				Command:     "PRIVMSG",
+				Params:      []string{"#chan"},
+				Trailing:    "hi",
+				HasTrailing: true,
+			},
+		},
+		{
+			name: "trailing whitespace in line",
+			line: "PING :irc.example.com \r\n",
+			want: &Message{Command: "PING", Trailing: "irc.example.com ", HasTrailing: true},
+		},
+		{
+			name: "no trailing parameter",
+			line: "CAP END",
+			want: &Message{Command: "CAP", Params: []string{"END"}},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			got, err := Parse(c.line)
+			if err != nil {
+				t.Fatalf("Parse(%q) returned error: %v", c.line, err)
+			}
+			if !reflect.DeepEqual(got, c.want) {
+				t.Errorf("Parse(%q) = %+v, want %+v", c.line, got, c.want)
+			}
+		})
+	}
+}
+
+func TestParse_empty(t *testing.T) {
+	if _, err := Parse(""); err == nil {
+		t.Error("expected an error for an empty line")
+	}
+	if _, err := Parse("\r\n"); err == nil {
+		t.Error("expected an error for a line with only CRLF")
+	}
+}
+
+func TestMessage_Nick(t *testing.T) {
+	msg := &Message{Prefix: "alice!user@host"}
+	if got := msg.Nick(); got != "alice" {
+		t.Errorf("Nick() = %q, want %q", got, "alice")
+	}
+
+	serverMsg := &Message{Prefix: "irc.example.com"}
+	if got := serverMsg.Nick(); got != "irc.example.com" {
+		t.Errorf("Nick() = %q, want %q", got, "irc.example.com")
+	}
+}
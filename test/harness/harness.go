@@ -0,0 +1,202 @@
+// Package harness launches a Mailpit SMTP sink as a subprocess and queries
+// its HTTP API, so integration tests can exercise the real SMTP notifier
+// path end-to-end instead of mocking Notifier at the method level. It's
+// modeled on goalert's smoke-test harness: a thin wrapper around a
+// disposable mail server plus a couple of assertion helpers.
+package harness
+
+import (
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// Mailpit wraps a running Mailpit subprocess: an SMTP listener to send
+// notifications to, and an HTTP API to assert on what arrived.
+type Mailpit struct {
+	SMTPHost string
+	SMTPPort string
+
+	apiBase string
+	cmd     *exec.Cmd
+}
+
+// StartMailpit launches a Mailpit subprocess bound to loopback ephemeral
+// ports and waits for its API to respond. If no "mailpit" binary is on
+// PATH, it skips the calling test rather than failing it, since Mailpit
+// isn't installed in every environment this suite runs in.
+func StartMailpit(t *testing.T) *Mailpit {
+	t.Helper()
+
+	bin, err := exec.LookPath("mailpit")
+	if err != nil {
+		t.Skip("mailpit binary not found on PATH, skipping integration test")
+	}
+
+	smtpPort := freePort(t)
+	httpPort := freePort(t)
+
+	cmd := exec.Command(bin,
+		"--smtp", fmt.Sprintf("127.0.0.1:%d", smtpPort),
+		"--listen", fmt.Sprintf("127.0.0.1:%d", httpPort),
+		"--db-file", filepath.Join(t.TempDir(), "mailpit.db"),
+	)
+	if err := cmd.Start(); err != nil {
+		t.Fatalf("starting mailpit: %v", err)
+	}
+
+	m := &Mailpit{
+		SMTPHost: "127.0.0.1",
+		SMTPPort: fmt.Sprintf("%d", smtpPort),
+		apiBase:  fmt.Sprintf("http://127.0.0.1:%d/api/v1", httpPort),
+		cmd:      cmd,
+	}
+
+	t.Cleanup(func() {
+		if m.cmd.Process != nil {
+			m.cmd.Process.Kill()
+		}
+		m.cmd.Wait()
+	})
+
+	m.waitForReady(t)
+	return m
+}
+
+func freePort(t *testing.T) int {
+	t.Helper()
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("finding a free port: %v", err)
+	}
+	defer l.Close()
+	return l.Addr().(*net.TCPAddr).Port
+}
+
+func (m *Mailpit) waitForReady(t *testing.T) {
+	t.Helper()
+	deadline := time.Now().Add(10 * time.Second)
+	for time.Now().Before(deadline) {
+		resp, err := http.Get(m.apiBase + "/messages")
+		if err == nil {
+			resp.Body.Close()
+			return
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+	t.Fatal("mailpit API never became ready")
+}
+
+// message mirrors the subset of Mailpit's /api/v1/messages summary fields
+// this harness cares about.
+type message struct {
+	ID      string `json:"ID"`
+	From    addr   `json:"From"`
+	To      []addr `json:"To"`
+	Subject string `json:"Subject"`
+	Created string `json:"Created"`
+}
+
+type addr struct {
+	Address string `json:"Address"`
+}
+
+type messagesResponse struct {
+	Messages []message `json:"messages"`
+}
+
+// messageDetail mirrors the subset of /api/v1/message/{ID} this harness
+// cares about: the plain-text body, for keyword matching.
+type messageDetail struct {
+	Text string `json:"Text"`
+}
+
+// WaitForMail polls Mailpit until at least one message has been received
+// by to, or timeout elapses, and returns its full body text. It fails the
+// test (rather than returning an error) if nothing arrives in time.
+func (m *Mailpit) WaitForMail(t *testing.T, to string, timeout time.Duration) string {
+	t.Helper()
+
+	deadline := time.Now().Add(timeout)
+	for time.Now().Before(deadline) {
+		msgs := m.listMessages(t)
+		for _, msg := range msgs {
+			if !addressedTo(msg, to) {
+				continue
+			}
+			return m.bodyOf(t, msg.ID)
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+
+	t.Fatalf("no mail delivered to %s within %s", to, timeout)
+	return ""
+}
+
+// ExpectMail waits (up to 5s) for a message addressed to `to`, failing the
+// test if none arrives, then checks that the body of the first such message
+// Mailpit lists contains every one of keywords. Other messages to `to` are
+// not searched, and it does not keep waiting for a matching one.
+func (m *Mailpit) ExpectMail(t *testing.T, to string, keywords ...string) {
+	t.Helper()
+
+	body := m.WaitForMail(t, to, 5*time.Second)
+	for _, kw := range keywords {
+		if !strings.Contains(body, kw) {
+			t.Errorf("mail to %s missing expected keyword %q, body: %q", to, kw, body)
+		}
+	}
+}
+
+// MessageCount returns how many messages Mailpit has received so far.
+func (m *Mailpit) MessageCount(t *testing.T) int {
+	t.Helper()
+	return len(m.listMessages(t))
+}
+
+func addressedTo(msg message, to string) bool {
+	for _, a := range msg.To {
+		if a.Address == to {
+			return true
+		}
+	}
+	return false
+}
+
+func (m *Mailpit) listMessages(t *testing.T) []message {
+	t.Helper()
+
+	resp, err := http.Get(m.apiBase + "/messages")
+	if err != nil {
+		t.Fatalf("listing mailpit messages: %v", err)
+	}
+	defer resp.Body.Close()
+
+	var out messagesResponse
+	if err := json.NewDecoder(resp.Body).Decode(&out); err != nil {
+		t.Fatalf("decoding mailpit messages response: %v", err)
+	}
+	return out.Messages
+}
+
+func (m *Mailpit) bodyOf(t *testing.T, id string) string {
+	t.Helper()
+
+	resp, err := http.Get(m.apiBase + "/message/" + id)
+	if err != nil {
+		t.Fatalf("fetching mailpit message %s: %v", id, err)
+	}
+	defer resp.Body.Close()
+
+	var detail messageDetail
+	if err := json.NewDecoder(resp.Body).Decode(&detail); err != nil {
+		t.Fatalf("decoding mailpit message %s: %v", id, err)
+	}
+	return detail.Text
+}